@@ -0,0 +1,218 @@
+// Incremental backups to local folder, files are split in chunks and stored by sha256 hash
+// so unchanged data is writed only one time to many snapshots
+//
+// Store layout:
+//
+//	chunks/ab/ab12...ef  chunk data
+//	snapshots/<id>.json  snapshot manifest
+package backup
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoSnapshot error = errors.New("snapshot not exists")      // Snapshot id not found in store
+	ErrCorrupted  error = errors.New("chunk corrupted")          // Chunk hash not match with content
+	ErrNoChunk    error = errors.New("chunk not exists")         // Chunk referenced by snapshot not found
+	ErrPath       error = errors.New("invalid path in manifest") // File path escape restore folder
+
+	DefaultChunkSize = 1 << 20 // 1 MiB chunks, region and LevelDB files change in small blocks
+)
+
+// Snapshot file or folder
+type File struct {
+	Path    string      `json:"path"`             // Slash separated path
+	Mode    fs.FileMode `json:"mode"`             // File mode and type
+	ModTime time.Time   `json:"mod_time"`         // Last modification
+	Size    int64       `json:"size"`             // File size
+	Chunks  []string    `json:"chunks,omitempty"` // Chunks hash in order
+}
+
+// Snapshot manifest
+type Snapshot struct {
+	ID    string            `json:"id"`             // Snapshot id
+	Time  time.Time         `json:"time"`           // Snapshot creation
+	Tags  map[string]string `json:"tags,omitempty"` // Extra info, example server version
+	Size  int64             `json:"size"`           // Sum of files size
+	Files []File            `json:"files"`          // Files and folders sorted by path
+}
+
+// Get file by path
+func (snapshot Snapshot) File(name string) (*File, bool) {
+	index, ok := slices.BinarySearchFunc(snapshot.Files, name, func(file File, name string) int { return strings.Compare(file.Path, name) })
+	if !ok {
+		return nil, false
+	}
+	return &snapshot.Files[index], true
+}
+
+// Content-addressed chunk store in local folder
+type Store struct {
+	Root      string // Store folder
+	ChunkSize int    // Max chunk size, default is [DefaultChunkSize]
+
+	locker sync.RWMutex
+}
+
+// Open store in folder, folder is created if not exists
+func Open(root string) (*Store, error) {
+	for _, folder := range []string{"chunks", "snapshots"} {
+		if err := os.MkdirAll(filepath.Join(root, folder), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &Store{Root: root, ChunkSize: DefaultChunkSize}, nil
+}
+
+// Backup all files from fsys to new snapshot, only chunks not in store are writed.
+// Works with [os.DirFS] to server folder or overlayfs Upper layer
+func (store *Store) Backup(ctx context.Context, fsys fs.FS, tags map[string]string) (*Snapshot, error) {
+	store.locker.RLock()
+	defer store.locker.RUnlock()
+
+	snapshot := &Snapshot{Time: time.Now().UTC(), Tags: tags, Files: []File{}}
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if err = ctx.Err(); err != nil {
+			return err
+		} else if name == "." || !(entry.IsDir() || entry.Type().IsRegular()) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		file := File{Path: name, Mode: info.Mode(), ModTime: info.ModTime().UTC()}
+		if !entry.IsDir() {
+			if file.Chunks, file.Size, err = store.writeFile(ctx, fsys, name); err != nil {
+				return err
+			}
+			snapshot.Size += file.Size
+		}
+		snapshot.Files = append(snapshot.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(snapshot.Files, func(a, b File) int { return strings.Compare(a.Path, b.Path) })
+
+	// Snapshot id from creation time and random bytes, sorted by time
+	random := make([]byte, 4)
+	rand.Read(random)
+	snapshot.ID = snapshot.Time.Format("20060102T150405Z") + "-" + hex.EncodeToString(random)
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	} else if err = writeAtomic(store.snapshotPath(snapshot.ID), data); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Split file in chunks and write chunks not in store
+func (store *Store) writeFile(ctx context.Context, fsys fs.FS, name string) (chunks []string, size int64, err error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	chunkSize := store.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+
+	chunks, buff := []string{}, make([]byte, chunkSize)
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		n, err := io.ReadFull(file, buff)
+		if n > 0 {
+			hash, err := store.writeChunk(buff[:n])
+			if err != nil {
+				return nil, 0, err
+			}
+			chunks, size = append(chunks, hash), size+int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return chunks, size, nil
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+func (store *Store) writeChunk(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	chunkPath := store.chunkPath(hash)
+	if _, err := os.Stat(chunkPath); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(chunkPath), 0755); err != nil {
+		return "", err
+	}
+	return hash, writeAtomic(chunkPath, data)
+}
+
+// Read chunk and check hash
+func (store *Store) readChunk(hash string) ([]byte, error) {
+	data, err := os.ReadFile(store.chunkPath(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNoChunk, hash)
+		}
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != hash {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupted, hash)
+	}
+	return data, nil
+}
+
+func (store *Store) chunkPath(hash string) string {
+	if len(hash) < 2 {
+		return filepath.Join(store.Root, "chunks", hash)
+	}
+	return filepath.Join(store.Root, "chunks", hash[:2], hash)
+}
+
+func (store *Store) snapshotPath(id string) string {
+	return filepath.Join(store.Root, "snapshots", filepath.Base(id)+".json")
+}
+
+// Write to temporary file and rename, so store never has half writed files
+func writeAtomic(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,296 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Files changed between two snapshots
+type Diff struct {
+	Added    []string `json:"added"`    // Files only in new snapshot
+	Removed  []string `json:"removed"`  // Files only in old snapshot
+	Modified []string `json:"modified"` // Files with different content or mode
+}
+
+// Snapshots to keep on [*Store.Prune], snapshot is kept if match any rule.
+// If all rules are zero all snapshots are kept
+type Retention struct {
+	KeepLast   int           // Keep last N snapshots
+	KeepWithin time.Duration // Keep snapshots newer than duration
+}
+
+// List snapshots sorted by time, oldest first
+func (store *Store) List() ([]*Snapshot, error) {
+	entries, err := os.ReadDir(filepath.Join(store.Root, "snapshots"))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []*Snapshot{}
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !strings.HasPrefix(id, ".") {
+			snapshot, err := store.Get(id)
+			if err != nil {
+				return nil, err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	slices.SortFunc(snapshots, func(a, b *Snapshot) int { return a.Time.Compare(b.Time) })
+	return snapshots, nil
+}
+
+// Get snapshot manifest
+func (store *Store) Get(id string) (*Snapshot, error) {
+	data, err := os.ReadFile(store.snapshotPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNoSnapshot, id)
+		}
+		return nil, err
+	}
+	snapshot := &Snapshot{}
+	if err = json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	return snapshot, nil
+}
+
+// Write snapshot files to folder, folder content is replaced by snapshot files.
+// Files are writed and chunks checked in staging folder, so target is unchanged on error
+func (store *Store) Restore(ctx context.Context, id, target string) error {
+	store.locker.RLock()
+	defer store.locker.RUnlock()
+
+	snapshot, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	for _, file := range snapshot.Files {
+		if !fs.ValidPath(file.Path) || file.Path == "." {
+			return fmt.Errorf("%w: %q", ErrPath, file.Path)
+		}
+	}
+
+	target = filepath.Clean(target)
+	if err = os.MkdirAll(target, 0755); err != nil {
+		return err
+	}
+	parent, base := filepath.Dir(target), filepath.Base(target)
+	staging, err := os.MkdirTemp(parent, "."+base+"-restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+
+	for _, file := range snapshot.Files {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		fullPath := filepath.Join(staging, filepath.FromSlash(file.Path))
+		if file.Mode.IsDir() {
+			if err = os.MkdirAll(fullPath, file.Mode.Perm()|0700); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = store.restoreFile(fullPath, file); err != nil {
+			return err
+		}
+	}
+
+	// Set folders time after files writed
+	for _, file := range snapshot.Files {
+		if file.Mode.IsDir() {
+			os.Chtimes(filepath.Join(staging, filepath.FromSlash(file.Path)), file.ModTime, file.ModTime)
+		}
+	}
+	return swapFolder(staging, target)
+}
+
+// Move target entries to temporary folder and staging entries to target, old entries are
+// removed after all entries moved and moved back on error. Entries are moved and not target
+// because target can be mount point
+func swapFolder(staging, target string) error {
+	old, err := os.MkdirTemp(filepath.Dir(target), "."+filepath.Base(target)+"-old-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(old)
+
+	moved, replaced := []string{}, []string{}
+	rollback := func(err error) error {
+		for _, name := range moved {
+			os.Rename(filepath.Join(target, name), filepath.Join(staging, name))
+		}
+		for _, name := range replaced {
+			os.Rename(filepath.Join(old, name), filepath.Join(target, name))
+		}
+		return err
+	}
+
+	entries, err := os.ReadDir(target)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = os.Rename(filepath.Join(target, entry.Name()), filepath.Join(old, entry.Name())); err != nil {
+			return rollback(err)
+		}
+		replaced = append(replaced, entry.Name())
+	}
+
+	if entries, err = os.ReadDir(staging); err != nil {
+		return rollback(err)
+	}
+	for _, entry := range entries {
+		if err = os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(target, entry.Name())); err != nil {
+			return rollback(err)
+		}
+		moved = append(moved, entry.Name())
+	}
+	return nil
+}
+
+func (store *Store) restoreFile(fullPath string, file File) error {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	fileOpen, err := os.OpenFile(fullPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, file.Mode.Perm())
+	if err != nil {
+		return err
+	}
+	defer fileOpen.Close()
+
+	for _, hash := range file.Chunks {
+		data, err := store.readChunk(hash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Path, err)
+		} else if _, err = fileOpen.Write(data); err != nil {
+			return err
+		}
+	}
+	if err = fileOpen.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(fullPath, file.ModTime, file.ModTime)
+}
+
+// Compare two snapshots
+func (store *Store) Diff(oldID, newID string) (*Diff, error) {
+	oldSnapshot, err := store.Get(oldID)
+	if err != nil {
+		return nil, err
+	}
+	newSnapshot, err := store.Get(newID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{Added: []string{}, Removed: []string{}, Modified: []string{}}
+	for _, file := range newSnapshot.Files {
+		old, ok := oldSnapshot.File(file.Path)
+		if !ok {
+			diff.Added = append(diff.Added, file.Path)
+		} else if old.Mode != file.Mode || !slices.Equal(old.Chunks, file.Chunks) {
+			diff.Modified = append(diff.Modified, file.Path)
+		}
+	}
+	for _, file := range oldSnapshot.Files {
+		if _, ok := newSnapshot.File(file.Path); !ok {
+			diff.Removed = append(diff.Removed, file.Path)
+		}
+	}
+	return diff, nil
+}
+
+// Check if all chunks from snapshot exists and content match hash,
+// if id is blank check all snapshots
+func (store *Store) Verify(ctx context.Context, id string) error {
+	store.locker.RLock()
+	defer store.locker.RUnlock()
+
+	snapshots := []*Snapshot{}
+	if id == "" {
+		var err error
+		if snapshots, err = store.List(); err != nil {
+			return err
+		}
+	} else {
+		snapshot, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	errs, checked := []error{}, map[string]error{}
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			for _, hash := range file.Chunks {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				err, ok := checked[hash]
+				if !ok {
+					_, err = store.readChunk(hash)
+					checked[hash] = err
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %w", snapshot.ID, file.Path, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Delete snapshots not matched by retention and chunks not used by any snapshot,
+// return deleted snapshots id
+func (store *Store) Prune(retention Retention) ([]string, error) {
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	snapshots, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	removed, used := []string{}, map[string]bool{}
+	for index, snapshot := range snapshots {
+		keep := retention.KeepLast <= 0 && retention.KeepWithin <= 0
+		keep = keep || (retention.KeepLast > 0 && index >= len(snapshots)-retention.KeepLast)
+		keep = keep || (retention.KeepWithin > 0 && time.Since(snapshot.Time) <= retention.KeepWithin)
+		if !keep {
+			if err = os.Remove(store.snapshotPath(snapshot.ID)); err != nil {
+				return removed, err
+			}
+			removed = append(removed, snapshot.ID)
+			continue
+		}
+		for _, file := range snapshot.Files {
+			for _, hash := range file.Chunks {
+				used[hash] = true
+			}
+		}
+	}
+
+	// Remove chunks not referenced
+	err = filepath.WalkDir(filepath.Join(store.Root, "chunks"), func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		} else if !used[entry.Name()] {
+			return os.Remove(fullPath)
+		}
+		return nil
+	})
+	return removed, err
+}
@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		fullPath := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(fullPath), 0755)
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx, cwd := context.Background(), t.TempDir()
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.ChunkSize = 8
+
+	writeFiles(t, cwd, map[string]string{
+		"server.properties":      "level-name=world",
+		"world/level.dat":        strings.Repeat("level", 10),
+		"world/region/r.0.0.mca": strings.Repeat("chunk", 20),
+	})
+	first, err := store.Backup(ctx, os.DirFS(cwd), map[string]string{"version": "1.21.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Count chunks to check deduplication
+	countChunks := func() (count int) {
+		filepath.WalkDir(filepath.Join(store.Root, "chunks"), func(_ string, entry os.DirEntry, _ error) error {
+			if !entry.IsDir() {
+				count++
+			}
+			return nil
+		})
+		return
+	}
+	chunks := countChunks()
+
+	writeFiles(t, cwd, map[string]string{"world/level.dat": strings.Repeat("LEVEL", 10), "ops.json": "[]"})
+	os.Remove(filepath.Join(cwd, "server.properties"))
+	second, err := store.Backup(ctx, os.DirFS(cwd), nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if added := countChunks() - chunks; added > 8 {
+		t.Errorf("unchanged files writed again, %d new chunks", added)
+	}
+
+	if list, err := store.List(); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 || list[0].ID != first.ID || list[0].Tags["version"] != "1.21.4" {
+		t.Errorf("invalid snapshots list: %+v", list)
+	}
+
+	diff, err := store.Diff(first.ID, second.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(diff.Added, []string{"ops.json"}) || !slices.Equal(diff.Removed, []string{"server.properties"}) || !slices.Equal(diff.Modified, []string{"world/level.dat"}) {
+		t.Errorf("invalid diff: %+v", diff)
+	}
+
+	// Restore, files not in snapshot are removed
+	target := filepath.Join(t.TempDir(), "world")
+	os.MkdirAll(filepath.Join(target, "world/playerdata"), 0755)
+	os.WriteFile(filepath.Join(target, "world/playerdata/extra.dat"), []byte("extra"), 0644)
+	os.WriteFile(filepath.Join(target, "ops.json"), []byte("[]"), 0644)
+	if err = store.Restore(ctx, first.ID, target); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "world/region/r.0.0.mca")); string(data) != strings.Repeat("chunk", 20) {
+		t.Errorf("invalid restored file: %q", data)
+	} else if data, _ = os.ReadFile(filepath.Join(target, "world/level.dat")); string(data) != strings.Repeat("level", 10) {
+		t.Errorf("invalid restored file: %q", data)
+	} else if _, err = os.Stat(filepath.Join(target, "world/playerdata")); !os.IsNotExist(err) {
+		t.Errorf("folder not in snapshot kept: %v", err)
+	} else if _, err = os.Stat(filepath.Join(target, "ops.json")); !os.IsNotExist(err) {
+		t.Errorf("file not in snapshot kept: %v", err)
+	}
+
+	// Corrupted chunk keep target unchanged
+	os.WriteFile(filepath.Join(target, "ops.json"), []byte("[]"), 0644)
+	chunk := first.Files[slices.IndexFunc(first.Files, func(file File) bool { return file.Path == "world/level.dat" })].Chunks[0]
+	chunkPath := filepath.Join(store.Root, "chunks", chunk[:2], chunk)
+	original, _ := os.ReadFile(chunkPath)
+	os.WriteFile(chunkPath, []byte("corrupted"), 0644)
+	if err = store.Restore(ctx, first.ID, target); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("expected corrupted chunk, got %v", err)
+	} else if data, _ := os.ReadFile(filepath.Join(target, "ops.json")); string(data) != "[]" {
+		t.Errorf("target changed on failed restore: %q", data)
+	} else if data, _ = os.ReadFile(filepath.Join(target, "world/level.dat")); string(data) != strings.Repeat("level", 10) {
+		t.Errorf("target changed on failed restore: %q", data)
+	} else if entries, _ := os.ReadDir(filepath.Dir(target)); len(entries) != 1 {
+		t.Errorf("staging folders not removed: %v", entries)
+	}
+	os.WriteFile(chunkPath, original, 0644)
+
+	// Prune and verify
+	if err = store.Verify(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := store.Prune(Retention{KeepLast: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(removed, []string{first.ID}) {
+		t.Errorf("invalid removed snapshots: %v", removed)
+	} else if _, err = store.Get(first.ID); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("snapshot not removed: %v", err)
+	} else if err = store.Verify(ctx, second.ID); err != nil {
+		t.Errorf("chunks used by kept snapshot removed: %v", err)
+	}
+	if removed, _ = store.Prune(Retention{KeepWithin: time.Hour}); len(removed) != 0 {
+		t.Errorf("new snapshot removed: %v", removed)
+	}
+
+	// Corrupt chunk
+	file, _ := second.File("world/level.dat")
+	os.WriteFile(store.chunkPath(file.Chunks[0]), []byte("broken"), 0644)
+	if err = store.Verify(ctx, second.ID); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("corrupted chunk not detected: %v", err)
+	}
+}
@@ -3,14 +3,28 @@ package allaymc
 import (
 	"archive/tar"
 	"archive/zip"
+	"context"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/regex"
+)
+
+var (
+	_ server.Server    = &AllayMC{}
+	_ logs.StreamParse = startedParse{}
+
+	StartedMatch *regex.Regexp = regex.MustCompile(`Server started in [0-9\.,]+ ?m?s`) // AllayMC started line
 )
 
 // Prepare AllayMC with basic setup to struct
@@ -23,13 +37,13 @@ func NewAllayMC(version *Version, versionFolder, javaFolder, cwd string) (*Allay
 
 	serverFile := filepath.Join(versionFolder, version.Version, "server.jar")
 	if !file_checker.IsFile(serverFile) {
-		if err := version.Dowload(serverFile); err != nil {
+		if err := version.Dowload(context.Background(), serverFile, nil); err != nil {
 			return nil, err
 		}
 	}
 
 	// Java bin path
-	javaPath, err := version.JavaVersion.Install(filepath.Join(javaFolder, strconv.Itoa(int(version.JavaVersion))))
+	javaPath, err := version.JavaVersion.Install(context.Background(), filepath.Join(javaFolder, strconv.Itoa(int(version.JavaVersion))), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -51,9 +65,12 @@ func NewAllayMC(version *Version, versionFolder, javaFolder, cwd string) (*Allay
 }
 
 type AllayMC struct {
-	PID         exec.Proc     // process status
-	ServerStart exec.ProcExec // Server command
-	Version     *Version      // Server version
+	PID         exec.Proc          // process status
+	ServerStart exec.ProcExec      // Server command
+	StopConfig  server.StopOptions // Stop stages config
+	Version     *Version           // Server version
+
+	restored *server.RestorePoint // Files replaced by Restore
 }
 
 // Make server backup with [*archive/tar.Writer]
@@ -70,10 +87,89 @@ func (allay AllayMC) Zip(w io.Writer) error {
 	return wr.AddFS(os.DirFS(allay.ServerStart.Cwd))
 }
 
-func (allay *AllayMC) Start() error {
+// Start server
+func (allay *AllayMC) Start(ctx context.Context) (err error) {
 	// if server not configured correctly return error
 	if allay == nil || allay.PID == nil {
 		return errors.New("cannot start server, server proc not defined")
 	}
+
+	// Keep or rollback restored files
+	defer func() { err = allay.restored.Started(err, allay.PID, startedParse{}) }()
+	if err = ctx.Err(); err != nil {
+		return err
+	}
 	return allay.PID.Start(allay.ServerStart)
 }
+
+// Restore backup from [AllayMC.Tar] or [AllayMC.Zip], server must be stopped.
+//
+// Files are restored to server folder,
+// old files are kept until restored server start, if start fail or server exit before old files are restored
+func (allay *AllayMC) Restore(r io.Reader, format server.ArchiveFormat) error {
+	if allay == nil {
+		return server.ErrNoProc
+	} else if server.Running(allay.PID) {
+		return server.ErrRunning
+	}
+
+	target := allay.ServerStart.Cwd
+	point, err := server.RestoreArchive(r, format, target, server.LayoutAny("server-settings.yml", "worlds"))
+	if err != nil {
+		return err
+	}
+	allay.restored.Wait()
+	allay.restored.Commit() // Previous restore not started
+	allay.restored = point
+	return nil
+}
+
+// Stop server with StopConfig stages ("stop" command, SIGINT and kill),
+// return stage that ended server and exit code
+func (allay *AllayMC) Stop(ctx context.Context) (*server.StopStatus, error) {
+	if allay == nil || allay.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.Stop(ctx, allay.PID, allay.StopConfig)
+}
+
+// Write command to server console and return lines printed in response
+func (allay *AllayMC) RunCommand(ctx context.Context, command string) ([]string, error) {
+	if allay == nil || allay.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.RunCommand(ctx, allay.PID, command, server.CommandOptions{ParseLine: javalog.ParseLine, Terminator: commandEnd})
+}
+
+// AllayMC print "Unknown command" on invalid command
+func commandEnd(line *logs.Line) bool { return strings.HasPrefix(line.Message, "Unknown command") }
+
+// Emit only [logs.EventStarted] when AllayMC print started line
+type startedParse struct{}
+
+func (startedParse) Flush() []logs.Event { return nil }
+func (startedParse) Next(line string) []logs.Event {
+	if StartedMatch.MatchString(line) {
+		return []logs.Event{{Type: logs.EventStarted, Time: time.Now()}}
+	}
+	return nil
+}
+
+// Wait server process end
+func (allay *AllayMC) Wait() error {
+	if allay == nil || allay.PID == nil {
+		return server.ErrNoProc
+	}
+	return allay.PID.Wait()
+}
+
+// Server process
+func (allay *AllayMC) Proc() exec.Proc { return allay.PID }
+
+// Server version
+func (allay *AllayMC) ServerVersion() string {
+	if allay.Version == nil {
+		return ""
+	}
+	return allay.Version.Version
+}
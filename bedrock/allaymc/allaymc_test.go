@@ -3,6 +3,8 @@ package allaymc
 import (
 	"encoding/json"
 	"testing"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
 )
 
 func TestListVersions(t *testing.T) {
@@ -15,3 +17,11 @@ func TestListVersions(t *testing.T) {
 	d, _ := json.MarshalIndent(vers, "", "  ")
 	t.Log(string(d))
 }
+
+func TestStartedParse(t *testing.T) {
+	if events := (startedParse{}).Next("[12:00:01.250] [main] INFO  Server started in 3512ms"); len(events) != 1 || events[0].Type != logs.EventStarted {
+		t.Errorf("started line not detected: %v", events)
+	} else if events = (startedParse{}).Next(`[12:00:01] [Server thread/INFO]: Done (3.5s)! For help, type "help"`); len(events) != 0 {
+		t.Errorf("java line detected as AllayMC: %v", events)
+	}
+}
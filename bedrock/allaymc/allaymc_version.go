@@ -2,9 +2,12 @@ package allaymc
 
 import (
 	"bytes"
+	"context"
 	"path"
 	"sync"
+	"time"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/github"
@@ -16,15 +19,15 @@ type Version struct {
 	Version     string                   `json:"version"`      // Server version
 	JavaVersion javaprebuild.JavaVersion `json:"java_version"` // Java Version, example: 21
 	ServerURL   string                   `json:"download"`     // Server file to download
+	Release     time.Time                `json:"release_date"` // Github release date
 }
 
 func (ver Version) SemverVersion() semver.Version { return semver.New(ver.Version) }
 
-// Download server
-func (ver Version) Dowload(path string) error {
+// Download server, progress can be nil
+func (ver Version) Dowload(ctx context.Context, path string, progress cache.Progress) error {
 	if ver.ServerURL != "" {
-		_, err := request.SaveAs(ver.ServerURL, path, nil)
-		return err
+		return cache.Default.SaveAs(ctx, ver.ServerURL, cache.Hash{}, path, nil, progress)
 	}
 	return ErrNoVersion
 }
@@ -32,7 +35,7 @@ func (ver Version) Dowload(path string) error {
 // Slice with all versions possibles
 type Versions []*Version
 
-func processVersionWorker(versions *Versions, jobs <-chan *github.Release, err *error, wg *sync.WaitGroup) {
+func processVersionWorker(versions *Versions, jobs <-chan *github.Release, err *error, locker *sync.Mutex, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for releaseInfo := range jobs {
 		for _, asset := range releaseInfo.Assets {
@@ -55,24 +58,33 @@ func processVersionWorker(versions *Versions, jobs <-chan *github.Release, err *
 			}
 
 			// append to versions
+			locker.Lock()
 			*versions = append(*versions, &Version{
 				Version:     releaseInfo.TagName,
 				ServerURL:   asset.BrowserDownloadURL,
 				JavaVersion: jarVersion,
+				Release:     releaseInfo.PublishedAt,
 			})
+			locker.Unlock()
 			break
 		}
 	}
 }
 
-// Fetch all versions from github releases
+// Fetch versions from github releases and append new releases,
+// releases already in versions are not downloaded again
 func (versions *Versions) FetchFromGithub() error {
 	jobs, wg, err := make(chan *github.Release, 12), sync.WaitGroup{}, error(nil)
+	known := map[string]bool{}
+	for _, version := range *versions {
+		known[version.Version] = true
+	}
 
 	// Start workers to process server versions
+	var locker sync.Mutex
 	for range 15 {
 		wg.Add(1)
-		go processVersionWorker(versions, jobs, &err, &wg)
+		go processVersionWorker(versions, jobs, &err, &locker, &wg)
 	}
 
 	// Make basic client to github APIs
@@ -81,7 +93,7 @@ func (versions *Versions) FetchFromGithub() error {
 		if err != nil {
 			close(jobs)
 			return err
-		} else if releaseInfo.TagName == "nightly" {
+		} else if releaseInfo.TagName == "nightly" || known[releaseInfo.TagName] {
 			continue
 		}
 		jobs <- releaseInfo
@@ -0,0 +1,231 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+)
+
+// Read allowlist.json, if file not exists return empty list
+func LoadAllowList(file string) (AllowList, error) {
+	list := AllowList{}
+	if err := loadJSON(file, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Write allowlist.json
+func (list AllowList) Save(file string) error { return saveJSON(file, list) }
+
+// Get player by name, names are case insensitive
+func (list AllowList) Get(name string) (*PlayerAllowList, bool) {
+	index := slices.IndexFunc(list, func(player PlayerAllowList) bool { return strings.EqualFold(player.Name, name) })
+	if index == -1 {
+		return nil, false
+	}
+	return &list[index], true
+}
+
+// Add player or update if name already in list
+func (list *AllowList) Add(player PlayerAllowList) {
+	if current, ok := list.Get(player.Name); ok {
+		if player.XUID == "" {
+			player.XUID = current.XUID
+		}
+		*current = player
+		return
+	}
+	*list = append(*list, player)
+}
+
+// Remove player from list, return false if player not exists
+func (list *AllowList) Remove(name string) bool {
+	size := len(*list)
+	*list = slices.DeleteFunc(*list, func(player PlayerAllowList) bool { return strings.EqualFold(player.Name, name) })
+	return size != len(*list)
+}
+
+// Fill XUID to players without it from players parsed in server log,
+// like [sirherobrine23.com.br/go-bds/go-bds/logs/bedrock.BedrockParse.Players].
+// Return true if any player updated
+func (list AllowList) FillXUID(players map[string][]logs.Player) (updated bool) {
+	for index := range list {
+		if list[index].XUID != "" {
+			continue
+		}
+		for name, actions := range players {
+			if !strings.EqualFold(name, list[index].Name) {
+				continue
+			}
+			for _, player := range actions {
+				if player.XUID() > 0 {
+					list[index].XUID, updated = strconv.FormatInt(player.XUID(), 10), true
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+// Read permissions.json, if file not exists return empty list
+func LoadPermissions(file string) (Permissions, error) {
+	list := Permissions{}
+	if err := loadJSON(file, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Write permissions.json
+func (list Permissions) Save(file string) error { return saveJSON(file, list) }
+
+// Get player permission by XUID
+func (list Permissions) Get(xuid string) (PermissionLevel, bool) {
+	index := slices.IndexFunc(list, func(permission Permission) bool { return permission.XUID == xuid })
+	if index == -1 {
+		return Visitor, false
+	}
+	return list[index].Permission, true
+}
+
+// Add or update player permission
+func (list *Permissions) Set(xuid string, level PermissionLevel) {
+	index := slices.IndexFunc(*list, func(permission Permission) bool { return permission.XUID == xuid })
+	if index == -1 {
+		*list = append(*list, Permission{XUID: xuid, Permission: level})
+		return
+	}
+	(*list)[index].Permission = level
+}
+
+// Remove player permission, return false if player not exists
+func (list *Permissions) Remove(xuid string) bool {
+	size := len(*list)
+	*list = slices.DeleteFunc(*list, func(permission Permission) bool { return permission.XUID == xuid })
+	return size != len(*list)
+}
+
+// Read allowlist.json from server folder
+func (bed *Bedrock) AllowList() (AllowList, error) {
+	return LoadAllowList(filepath.Join(bed.ServerStart.Cwd, "allowlist.json"))
+}
+
+// Write allowlist.json to server folder and reload if server running
+func (bed *Bedrock) SetAllowList(ctx context.Context, list AllowList) error {
+	if err := list.Save(filepath.Join(bed.ServerStart.Cwd, "allowlist.json")); err != nil {
+		return err
+	}
+	return bed.reload(ctx, "allowlist reload")
+}
+
+// Add or update player in allowlist.json
+func (bed *Bedrock) AllowPlayer(ctx context.Context, player PlayerAllowList) error {
+	list, err := bed.AllowList()
+	if err != nil {
+		return err
+	}
+	list.Add(player)
+	return bed.SetAllowList(ctx, list)
+}
+
+// Remove player from allowlist.json
+func (bed *Bedrock) DisallowPlayer(ctx context.Context, name string) error {
+	list, err := bed.AllowList()
+	if err != nil {
+		return err
+	} else if !list.Remove(name) {
+		return logs.ErrPlayerNotExist
+	}
+	return bed.SetAllowList(ctx, list)
+}
+
+// Fill XUID to players in allowlist.json from players parsed in server log
+func (bed *Bedrock) FillAllowListXUID(ctx context.Context, players map[string][]logs.Player) error {
+	list, err := bed.AllowList()
+	if err != nil {
+		return err
+	} else if !list.FillXUID(players) {
+		return nil
+	}
+	return bed.SetAllowList(ctx, list)
+}
+
+// Read permissions.json from server folder
+func (bed *Bedrock) Permissions() (Permissions, error) {
+	return LoadPermissions(filepath.Join(bed.ServerStart.Cwd, "permissions.json"))
+}
+
+// Write permissions.json to server folder and reload if server running
+func (bed *Bedrock) SetPermissions(ctx context.Context, list Permissions) error {
+	if err := list.Save(filepath.Join(bed.ServerStart.Cwd, "permissions.json")); err != nil {
+		return err
+	}
+	return bed.reload(ctx, "permission reload")
+}
+
+// Add or update player permission in permissions.json
+func (bed *Bedrock) SetPermission(ctx context.Context, xuid string, level PermissionLevel) error {
+	list, err := bed.Permissions()
+	if err != nil {
+		return err
+	}
+	list.Set(xuid, level)
+	return bed.SetPermissions(ctx, list)
+}
+
+// Remove player from permissions.json
+func (bed *Bedrock) RemovePermission(ctx context.Context, xuid string) error {
+	list, err := bed.Permissions()
+	if err != nil {
+		return err
+	} else if !list.Remove(xuid) {
+		return logs.ErrPlayerNotExist
+	}
+	return bed.SetPermissions(ctx, list)
+}
+
+// Send reload command if server running, files are read on start if not running
+func (bed *Bedrock) reload(ctx context.Context, command string) error {
+	if bed.PID == nil {
+		return nil
+	} else if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := bed.PID.Write([]byte(command + "\n"))
+	if errors.Is(err, exec.ErrNoRunning) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func loadJSON(file string, target any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	} else if len(strings.TrimSpace(string(data))) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, target)
+}
+
+func saveJSON(file string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
@@ -0,0 +1,185 @@
+package bedrock
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSaveQuery error = errors.New("invalid save query response")  // Cannot parse files list from "save query"
+	ErrSaveFile  error = errors.New("file smaller than save query") // World file is smaller than reported length
+
+	SaveQueryInterval = time.Second      // Time to wait between "save query" attempts
+	SaveResumeTimeout = 30 * time.Second // Max time to "save resume" after backup
+)
+
+// World file reported by "save query", path is relative to worlds folder
+type SaveFile struct {
+	Path string `json:"path"` // File path, example "Bedrock level/db/000005.ldb"
+	Size int64  `json:"size"` // Bytes to copy, file can be bigger while server hold saves
+}
+
+// Parse "save query" response, return false if files not ready to copy
+//
+//	Data saved. Files are now ready to be copied.
+//	Bedrock level/db/000005.ldb:1234, Bedrock level/db/CURRENT:16, Bedrock level/level.dat:2500
+func ParseSaveQuery(lines []string) ([]SaveFile, bool, error) {
+	for index, line := range lines {
+		if !strings.Contains(line, "Files are now ready to be copied") {
+			continue
+		} else if index+1 >= len(lines) {
+			return nil, false, fmt.Errorf("%w: files list not found", ErrSaveQuery)
+		}
+
+		files := []SaveFile{}
+		for entry := range strings.SplitSeq(lines[index+1], ", ") {
+			sep := strings.LastIndex(entry, ":")
+			if sep == -1 {
+				return nil, false, fmt.Errorf("%w: %q", ErrSaveQuery, entry)
+			}
+			size, err := strconv.ParseInt(strings.TrimSpace(entry[sep+1:]), 10, 64)
+			if err != nil {
+				return nil, false, fmt.Errorf("%w: %q", ErrSaveQuery, entry)
+			}
+			files = append(files, SaveFile{Path: strings.TrimSpace(entry[:sep]), Size: size})
+		}
+		return files, true, nil
+	}
+	return nil, false, nil
+}
+
+// Run "save hold" and "save query" until server release files,
+// resume function must be called after copy files to run "save resume"
+func (bed *Bedrock) SaveHold(ctx context.Context) (files []SaveFile, resume func() error, err error) {
+	resume = func() error {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), SaveResumeTimeout)
+		defer cancel()
+		_, err := bed.RunCommand(ctx, "save resume")
+		return err
+	}
+
+	if _, err = bed.RunCommand(ctx, "save hold"); err != nil {
+		return nil, nil, errors.Join(err, resume())
+	}
+
+	for {
+		lines, err := bed.RunCommand(ctx, "save query")
+		if err != nil {
+			return nil, nil, errors.Join(err, resume())
+		}
+		files, ready, err := ParseSaveQuery(lines)
+		if err != nil {
+			return nil, nil, errors.Join(err, resume())
+		} else if ready {
+			return files, resume, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, nil, errors.Join(ctx.Err(), resume())
+		case <-time.After(SaveQueryInterval):
+		}
+	}
+}
+
+// Make world backup with [*archive/tar.Writer] from running server,
+// only files reported by "save query" are archived in "worlds" folder
+func (bed *Bedrock) HotTar(ctx context.Context, w io.Writer) error {
+	tarball := tar.NewWriter(w)
+	return bed.hotBackup(ctx, tarball.Close, func(name string, info os.FileInfo, size int64) (io.Writer, error) {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return nil, err
+		}
+		header.Name, header.Size = name, size
+		return tarball, tarball.WriteHeader(header)
+	})
+}
+
+// Make world backup with [*archive/zip.Writer] from running server,
+// only files reported by "save query" are archived in "worlds" folder
+func (bed *Bedrock) HotZip(ctx context.Context, w io.Writer) error {
+	wr := zip.NewWriter(w)
+	return bed.hotBackup(ctx, wr.Close, func(name string, info os.FileInfo, _ int64) (io.Writer, error) {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return nil, err
+		}
+		header.Name, header.Method = name, zip.Deflate
+		return wr.CreateHeader(header)
+	})
+}
+
+// Hold saves, copy files truncated to reported size and always resume saves
+func (bed *Bedrock) hotBackup(ctx context.Context, closeArchive func() error, create func(name string, info os.FileInfo, size int64) (io.Writer, error)) (err error) {
+	files, resume, err := bed.SaveHold(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, resume()) }()
+
+	for _, file := range files {
+		if err = ctx.Err(); err != nil {
+			return err
+		} else if err = copySaveFile(bed.ServerStart.Cwd, file, create); err != nil {
+			return err
+		}
+	}
+	return closeArchive()
+}
+
+func copySaveFile(cwd string, file SaveFile, create func(name string, info os.FileInfo, size int64) (io.Writer, error)) error {
+	reader, err := OpenSaveFile(os.DirFS(cwd), file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	w, err := create(path.Join("worlds", file.Path), reader.Info, file.Size)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, reader)
+	return err
+}
+
+// World file opened from server folder, reads stop at size reported by "save query"
+type SaveReader struct {
+	fs.File
+	Info   fs.FileInfo // Original file info, size can be bigger than reported
+	reader io.Reader
+}
+
+func (file *SaveReader) Read(p []byte) (int, error) { return file.reader.Read(p) }
+
+// Open file from "worlds" folder in fsys truncated to size reported by "save query",
+// return [ErrSaveFile] if file is smaller than reported
+func OpenSaveFile(fsys fs.FS, file SaveFile) (*SaveReader, error) {
+	if !fs.ValidPath(file.Path) || file.Path == "." {
+		return nil, &fs.PathError{Op: "open", Path: file.Path, Err: fs.ErrInvalid}
+	}
+
+	fileOpen, err := fsys.Open(path.Join("worlds", file.Path))
+	if err != nil {
+		return nil, err
+	}
+	info, err := fileOpen.Stat()
+	if err != nil {
+		fileOpen.Close()
+		return nil, err
+	} else if info.Size() < file.Size {
+		fileOpen.Close()
+		return nil, fmt.Errorf("%w: %s have %d bytes, expected %d", ErrSaveFile, file.Path, info.Size(), file.Size)
+	}
+	return &SaveReader{File: fileOpen, Info: info, reader: io.LimitReader(fileOpen, file.Size)}, nil
+}
@@ -0,0 +1,265 @@
+package bedrock
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/utils/properties"
+)
+
+var ErrInvalidProperty error = errors.New("invalid server.properties value") // Value out of range or not valid option
+
+type GameMode string        // Player game mode
+type Difficulty string      // World difficulty
+type ContentLogLevel string // Minimum level to log content errors
+
+const (
+	Survival  GameMode = "survival"
+	Creative  GameMode = "creative"
+	Adventure GameMode = "adventure"
+
+	Peaceful Difficulty = "peaceful"
+	Easy     Difficulty = "easy"
+	Normal   Difficulty = "normal"
+	Hard     Difficulty = "hard"
+
+	LogVerbose ContentLogLevel = "verbose"
+	LogInfo    ContentLogLevel = "info"
+	LogWarning ContentLogLevel = "warning"
+	LogError   ContentLogLevel = "error"
+)
+
+// Bedrock server.properties, keys documented in bedrock_server_how_to.html and
+// default server.properties from server zip
+type ServerProperties struct {
+	ServerName                    string            `properties:"server-name"`                          // Server name showed in game server list
+	GameMode                      GameMode          `properties:"gamemode"`                             // Game mode to new players
+	ForceGameMode                 bool              `properties:"force-gamemode"`                       // Force gamemode to players, not use world save game mode
+	Difficulty                    Difficulty        `properties:"difficulty"`                           // World difficulty
+	AllowCheats                   bool              `properties:"allow-cheats"`                         // Allow commands like /give to players
+	MaxPlayers                    int               `properties:"max-players"`                          // Max players connected
+	OnlineMode                    bool              `properties:"online-mode"`                          // Require Xbox Live authentication
+	AllowList                     bool              `properties:"allow-list"`                           // Only players in allowlist.json can connect
+	ServerPort                    int               `properties:"server-port"`                          // IPv4 port
+	ServerPortV6                  int               `properties:"server-portv6"`                        // IPv6 port
+	EnableLanVisibility           bool              `properties:"enable-lan-visibility"`                // Listen default ports to LAN discovery
+	ViewDistance                  int               `properties:"view-distance"`                        // Max view distance in chunks, minimum 5
+	TickDistance                  int               `properties:"tick-distance"`                        // Distance in chunks from players to world tick, 4 to 12
+	PlayerIdleTimeout             int               `properties:"player-idle-timeout"`                  // Minutes to kick idle players, 0 to disable
+	MaxThreads                    int               `properties:"max-threads"`                          // Max threads to server, 0 to use all
+	LevelName                     string            `properties:"level-name"`                           // World folder name in worlds
+	LevelSeed                     string            `properties:"level-seed"`                           // Seed to new world
+	DefaultPlayerPermissionLevel  PermissionLevel   `properties:"default-player-permission-level"`      // Permission to new players
+	TexturepackRequired           bool              `properties:"texturepack-required"`                 // Force players to use world resource packs
+	ContentLogFileEnabled         bool              `properties:"content-log-file-enabled"`             // Write content errors to file
+	ContentLogLevel               ContentLogLevel   `properties:"content-log-level"`                    // Minimum level to content log
+	ContentLogConsoleOutput       bool              `properties:"content-log-console-output-enabled"`   // Print content errors to stdout
+	CompressionThreshold          int               `properties:"compression-threshold"`                // Min packet size to compress, 0 to 65535
+	CompressionAlgorithm          string            `properties:"compression-algorithm"`                // "zlib" or "snappy"
+	ServerAuthoritativeMovement   string            `properties:"server-authoritative-movement"`        // "client-auth", "server-auth" or "server-auth-with-rewind"
+	PlayerPositionAcceptance      float64           `properties:"player-position-acceptance-threshold"` // Tolerance of client and server position difference
+	PlayerMovementActionDirection float64           `properties:"player-movement-action-direction-threshold"`
+	ServerAuthoritativeBlockBreak bool              `properties:"server-authoritative-block-breaking"` // Server check player block breaking
+	BlockBreakingPickRange        float64           `properties:"server-authoritative-block-breaking-pick-range-scalar"`
+	ChatRestriction               string            `properties:"chat-restriction"`                     // "None", "Dropped" or "Disabled"
+	DisablePlayerInteraction      bool              `properties:"disable-player-interaction"`           // Players ignore other players
+	ClientSideChunkGeneration     bool              `properties:"client-side-chunk-generation-enabled"` // Clients generate visual chunks out of interaction distance
+	BlockNetworkIDsAreHashes      bool              `properties:"block-network-ids-are-hashes"`         // Send block ids as hash
+	DisablePersona                bool              `properties:"disable-persona"`                      // Internal use only
+	DisableCustomSkins            bool              `properties:"disable-custom-skins"`                 // Disable skins made out of game
+	ServerBuildRadiusRatio        string            `properties:"server-build-radius-ratio"`            // "Disabled" or 0.0 to 1.0
+	AllowOutboundScriptDebugging  bool              `properties:"allow-outbound-script-debugging"`      // Allow script debugger connect command
+	AllowInboundScriptDebugging   bool              `properties:"allow-inbound-script-debugging"`       // Allow script debugger listen command
+	ScriptDebuggerAutoAttach      string            `properties:"script-debugger-auto-attach"`          // "disabled", "connect" or "listen"
+	ExtraKeys                     map[string]string `properties:"-"`                                    // Keys not mapped in struct, kept on save
+}
+
+// Default values from server.properties shipped with server
+func DefaultServerProperties() *ServerProperties {
+	return &ServerProperties{
+		ServerName:                    "Dedicated Server",
+		GameMode:                      Survival,
+		Difficulty:                    Easy,
+		MaxPlayers:                    10,
+		OnlineMode:                    true,
+		ServerPort:                    19132,
+		ServerPortV6:                  19133,
+		EnableLanVisibility:           true,
+		ViewDistance:                  32,
+		TickDistance:                  4,
+		PlayerIdleTimeout:             30,
+		MaxThreads:                    8,
+		LevelName:                     "Bedrock level",
+		DefaultPlayerPermissionLevel:  Member,
+		ContentLogLevel:               LogInfo,
+		CompressionThreshold:          1,
+		CompressionAlgorithm:          "zlib",
+		ServerAuthoritativeMovement:   "server-auth",
+		PlayerPositionAcceptance:      0.5,
+		PlayerMovementActionDirection: 0.85,
+		ServerAuthoritativeBlockBreak: true,
+		BlockBreakingPickRange:        1.5,
+		ChatRestriction:               "None",
+		ClientSideChunkGeneration:     true,
+		BlockNetworkIDsAreHashes:      true,
+		ServerBuildRadiusRatio:        "Disabled",
+		ScriptDebuggerAutoAttach:      "disabled",
+		ExtraKeys:                     map[string]string{},
+	}
+}
+
+// Read server.properties, keys not in file keep default value
+func LoadServerProperties(file string) (*ServerProperties, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := DefaultServerProperties()
+	return config, config.UnmarshalProperties(data)
+}
+
+// Decode properties and keep keys not mapped in ExtraKeys
+func (config *ServerProperties) UnmarshalProperties(data []byte) error {
+	reader := properties.NewParse(bytes.NewReader(data))
+	if err := reader.Decode(config); err != nil {
+		return err
+	}
+
+	values, err := reader.Values()
+	if err != nil {
+		return err
+	}
+	if config.ExtraKeys == nil {
+		config.ExtraKeys = map[string]string{}
+	}
+	known := propertiesKeys(reflect.TypeFor[ServerProperties]())
+	for key, value := range properties.Flat(values) {
+		if !slices.Contains(known, key) {
+			config.ExtraKeys[key] = value
+		}
+	}
+	return nil
+}
+
+// Encode properties with ExtraKeys in end of file
+func (config ServerProperties) MarshalProperties() ([]byte, error) {
+	data, err := properties.Marshal(&config)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(config.ExtraKeys))
+	for key := range config.ExtraKeys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		data = fmt.Appendf(data, "%s = %s\n", key, config.ExtraKeys[key])
+	}
+	return data, nil
+}
+
+// Validate values and write to file
+func (config ServerProperties) Save(file string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	data, err := config.MarshalProperties()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// Check enums and ranges, return all invalid values
+func (config ServerProperties) Validate() error {
+	errs := []error{}
+	invalid := func(key string, value any, expected string) {
+		errs = append(errs, fmt.Errorf("%w: %s = %v, expected %s", ErrInvalidProperty, key, value, expected))
+	}
+
+	if !slices.Contains([]GameMode{Survival, Creative, Adventure}, config.GameMode) {
+		invalid("gamemode", config.GameMode, "survival, creative or adventure")
+	}
+	if !slices.Contains([]Difficulty{Peaceful, Easy, Normal, Hard}, config.Difficulty) {
+		invalid("difficulty", config.Difficulty, "peaceful, easy, normal or hard")
+	}
+	if !slices.Contains([]ContentLogLevel{LogVerbose, LogInfo, LogWarning, LogError}, config.ContentLogLevel) {
+		invalid("content-log-level", config.ContentLogLevel, "verbose, info, warning or error")
+	}
+	if config.DefaultPlayerPermissionLevel.String() == "" {
+		invalid("default-player-permission-level", uint(config.DefaultPlayerPermissionLevel), "visitor, member or operator")
+	}
+	if !slices.Contains([]string{"zlib", "snappy"}, config.CompressionAlgorithm) {
+		invalid("compression-algorithm", config.CompressionAlgorithm, "zlib or snappy")
+	}
+	if !slices.Contains([]string{"client-auth", "server-auth", "server-auth-with-rewind"}, config.ServerAuthoritativeMovement) {
+		invalid("server-authoritative-movement", config.ServerAuthoritativeMovement, "client-auth, server-auth or server-auth-with-rewind")
+	}
+	if !slices.Contains([]string{"None", "Dropped", "Disabled"}, config.ChatRestriction) {
+		invalid("chat-restriction", config.ChatRestriction, "None, Dropped or Disabled")
+	}
+	if !slices.Contains([]string{"disabled", "connect", "listen"}, config.ScriptDebuggerAutoAttach) {
+		invalid("script-debugger-auto-attach", config.ScriptDebuggerAutoAttach, "disabled, connect or listen")
+	}
+	if ratio, err := strconv.ParseFloat(config.ServerBuildRadiusRatio, 64); config.ServerBuildRadiusRatio != "Disabled" && (err != nil || ratio < 0 || ratio > 1) {
+		invalid("server-build-radius-ratio", config.ServerBuildRadiusRatio, "Disabled or 0.0 to 1.0")
+	}
+
+	if config.MaxPlayers < 1 {
+		invalid("max-players", config.MaxPlayers, "1 or more")
+	}
+	if config.ServerPort < 1 || config.ServerPort > 65535 {
+		invalid("server-port", config.ServerPort, "1 to 65535")
+	}
+	if config.ServerPortV6 < 1 || config.ServerPortV6 > 65535 {
+		invalid("server-portv6", config.ServerPortV6, "1 to 65535")
+	}
+	if config.ViewDistance < 5 {
+		invalid("view-distance", config.ViewDistance, "5 or more")
+	}
+	if config.TickDistance < 4 || config.TickDistance > 12 {
+		invalid("tick-distance", config.TickDistance, "4 to 12")
+	}
+	if config.PlayerIdleTimeout < 0 {
+		invalid("player-idle-timeout", config.PlayerIdleTimeout, "0 or more")
+	}
+	if config.MaxThreads < 0 {
+		invalid("max-threads", config.MaxThreads, "0 or more")
+	}
+	if config.CompressionThreshold < 0 || config.CompressionThreshold > 65535 {
+		invalid("compression-threshold", config.CompressionThreshold, "0 to 65535")
+	}
+	if config.LevelName == "" || strings.ContainsAny(config.LevelName, `/\`) {
+		invalid("level-name", config.LevelName, "folder name")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Read server.properties from server folder
+func (bed *Bedrock) Properties() (*ServerProperties, error) {
+	return LoadServerProperties(filepath.Join(bed.ServerStart.Cwd, "server.properties"))
+}
+
+// Validate and write server.properties to server folder, server read file only on start
+func (bed *Bedrock) SetProperties(config *ServerProperties) error {
+	return config.Save(filepath.Join(bed.ServerStart.Cwd, "server.properties"))
+}
+
+// Keys from struct tags
+func propertiesKeys(structType reflect.Type) []string {
+	keys := []string{}
+	for index := range structType.NumField() {
+		if key := strings.Split(structType.Field(index).Tag.Get("properties"), ",")[0]; key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
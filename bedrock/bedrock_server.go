@@ -15,11 +15,16 @@ import (
 
 	"sirherobrine23.com.br/go-bds/go-bds/binfmt"
 	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	bedrocklog "sirherobrine23.com.br/go-bds/go-bds/logs/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/js_types"
 	"sirherobrine23.com.br/go-bds/overlayfs"
 )
 
+var _ server.Server = &Bedrock{}
+
 var currentPlatform = func() string {
 	bin, err := binfmt.Open(os.Args[0])
 	if err != nil {
@@ -31,9 +36,12 @@ var currentPlatform = func() string {
 type Bedrock struct {
 	PID            exec.Proc            // process status
 	ServerStart    exec.ProcExec        // Server command
+	StopConfig     server.StopOptions   // Stop stages config
 	Overlayfs      *overlayfs.Overlayfs // Overlayfs mounted
 	Version        *Version             // Server version
 	PlaformVersion *PlatformVersion     // Server version target
+
+	restored *server.RestorePoint // Files replaced by Restore
 }
 
 // Make new bedrock config
@@ -74,7 +82,7 @@ func NewBedrock(version *Version, versionFolder, cwd, upper, workdir string) (*B
 		bedrockConfig.PlaformVersion = target
 
 		if file_checker.FolderIsEmpty(versionFolder) {
-			if err := target.Extract(versionFolder); err != nil {
+			if err := target.Extract(context.Background(), versionFolder, nil); err != nil {
 				return nil, err
 			}
 		}
@@ -91,7 +99,7 @@ func NewBedrock(version *Version, versionFolder, cwd, upper, workdir string) (*B
 		bedrockConfig.PlaformVersion = target
 
 		if file_checker.FolderIsEmpty(versionFolder) {
-			if err := target.Extract(versionFolder); err != nil {
+			if err := target.Extract(context.Background(), versionFolder, nil); err != nil {
 				return nil, err
 			}
 		}
@@ -143,7 +151,7 @@ func NewBedrock(version *Version, versionFolder, cwd, upper, workdir string) (*B
 // Make server backup with [*archive/tar.Writer]
 //
 // If server mounted with [*sirherobrine23.com.br/go-bds/go-bds/overlayfs.Overlayfs] backup only Upper layer
-// else backup entire server folder
+// else backup entire server folder. To running server use [*Bedrock.HotTar]
 func (bed Bedrock) Tar(w io.Writer) error {
 	tarball := tar.NewWriter(w)
 	defer tarball.Close()
@@ -156,7 +164,7 @@ func (bed Bedrock) Tar(w io.Writer) error {
 // Make server backup with [*archive/zip.Writer]
 //
 // If server mounted with [*sirherobrine23.com.br/go-bds/go-bds/overlayfs.Overlayfs] backup only Upper layer
-// else backup entire server folder
+// else backup entire server folder. To running server use [*Bedrock.HotZip]
 func (bed Bedrock) Zip(w io.Writer) error {
 	wr := zip.NewWriter(w)
 	defer wr.Close()
@@ -167,12 +175,15 @@ func (bed Bedrock) Zip(w io.Writer) error {
 }
 
 // Start server
-func (bed *Bedrock) Start(ctx context.Context) error {
+func (bed *Bedrock) Start(ctx context.Context) (err error) {
 	// if server not configured correctly return error
 	if bed == nil || bed.PID == nil {
 		return errors.New("cannot start server, server proc not defined")
 	}
 
+	// Keep or rollback restored files
+	defer func() { err = bed.restored.Started(err, bed.PID, &bedrocklog.BedrockParse{}) }()
+
 	// If overlayfs configured mount before start server
 	if bed.Overlayfs != nil {
 		if err := bed.Overlayfs.Mount(ctx); err != nil {
@@ -198,8 +209,71 @@ func (bed *Bedrock) Start(ctx context.Context) error {
 	}
 
 	// Start server
-	if err := bed.PID.Start(bed.ServerStart); err != nil {
+	return bed.PID.Start(bed.ServerStart)
+}
+
+// Restore backup from [Bedrock.Tar] or [Bedrock.Zip], server must be stopped.
+//
+// If server mounted with [*sirherobrine23.com.br/go-bds/go-bds/overlayfs.Overlayfs] restore to Upper layer else to server folder,
+// old files are kept until restored server start, if start fail or server exit before old files are restored
+func (bed *Bedrock) Restore(r io.Reader, format server.ArchiveFormat) error {
+	if bed == nil {
+		return server.ErrNoProc
+	} else if server.Running(bed.PID) {
+		return server.ErrRunning
+	}
+
+	target := bed.ServerStart.Cwd
+	if bed.Overlayfs != nil {
+		target = bed.Overlayfs.Upper
+	}
+	point, err := server.RestoreArchive(r, format, target, server.LayoutAny("worlds", "server.properties"))
+	if err != nil {
 		return err
 	}
+	bed.restored.Wait()
+	bed.restored.Commit() // Previous restore not started
+	bed.restored = point
 	return nil
 }
+
+// Stop server with StopConfig stages ("stop" command, SIGINT and kill),
+// return stage that ended server and exit code
+func (bed *Bedrock) Stop(ctx context.Context) (*server.StopStatus, error) {
+	if bed == nil || bed.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.Stop(ctx, bed.PID, bed.StopConfig)
+}
+
+// Write command to server console and return lines printed in response
+func (bed *Bedrock) RunCommand(ctx context.Context, command string) ([]string, error) {
+	if bed == nil || bed.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.RunCommand(ctx, bed.PID, command, server.CommandOptions{ParseLine: bedrocklog.ParseLine, Terminator: commandEnd})
+}
+
+// Bedrock server print "Unknown command" or "Syntax error" on invalid command
+func commandEnd(line *logs.Line) bool {
+	return strings.HasPrefix(line.Message, "Unknown command") || strings.HasPrefix(line.Message, "Syntax error")
+}
+
+// Wait server process end
+func (bed *Bedrock) Wait() error {
+	if bed == nil || bed.PID == nil {
+		return server.ErrNoProc
+	}
+	return bed.PID.Wait()
+}
+
+// Server process
+func (bed *Bedrock) Proc() exec.Proc { return bed.PID }
+
+// Server version
+func (bed *Bedrock) ServerVersion() string {
+	if bed.Version == nil {
+		return ""
+	}
+	return bed.Version.Version
+}
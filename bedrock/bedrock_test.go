@@ -1,10 +1,21 @@
 package bedrock
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	bedrocklog "sirherobrine23.com.br/go-bds/go-bds/logs/bedrock"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 )
 
@@ -73,3 +84,158 @@ func TestExtractServer(t *testing.T) {
 		t.Error("invalid server installation")
 	}
 }
+
+func TestServerProperties(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.properties")
+	data := "# Comment to server.properties" + strings.Repeat("\n# Allowed values: Any string without semicolon symbol or symbols illegal for file name", 60) + `
+server-name=Go bds
+gamemode=creative
+difficulty=hard
+level-seed=123456
+default-player-permission-level=operator
+player-position-acceptance-threshold=0.25
+server-build-radius-ratio=0.5
+emit-server-telemetry=true
+client-side-chunk-generation.enabled=true
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadServerProperties(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if config.ServerName != "Go bds" || config.GameMode != Creative || config.Difficulty != Hard || config.LevelSeed != "123456" {
+		t.Errorf("invalid values: %+v", config)
+	} else if config.DefaultPlayerPermissionLevel != Operator || config.PlayerPositionAcceptance != 0.25 || config.ServerBuildRadiusRatio != "0.5" {
+		t.Errorf("invalid values: %+v", config)
+	} else if config.ServerPort != 19132 || config.LevelName != "Bedrock level" {
+		t.Errorf("default values not kept: %+v", config)
+	} else if config.ExtraKeys["emit-server-telemetry"] != "true" || config.ExtraKeys["client-side-chunk-generation.enabled"] != "true" {
+		t.Errorf("unknown key not kept: %v", config.ExtraKeys)
+	}
+
+	// Round-trip
+	if err = config.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	config2, err := LoadServerProperties(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(config, config2) {
+		t.Errorf("round-trip values diverge:\n%+v\n%+v", config, config2)
+	}
+
+	config.TickDistance, config.GameMode = 20, "hardcore"
+	if err = config.Validate(); !errors.Is(err, ErrInvalidProperty) || !strings.Contains(err.Error(), "tick-distance") || !strings.Contains(err.Error(), "gamemode") {
+		t.Errorf("invalid values not reported: %v", err)
+	}
+}
+
+func TestAllowList(t *testing.T) {
+	bed := &Bedrock{ServerStart: exec.ProcExec{Cwd: t.TempDir()}}
+	ctx := context.Background()
+
+	if list, err := bed.AllowList(); err != nil || len(list) != 0 {
+		t.Fatalf("allowlist.json not exists, expected empty list: %v, %v", list, err)
+	}
+	if err := bed.AllowPlayer(ctx, PlayerAllowList{Name: "Sirherobrine23"}); err != nil {
+		t.Fatal(err)
+	} else if err = bed.AllowPlayer(ctx, PlayerAllowList{Name: "Steve", XUID: "123"}); err != nil {
+		t.Fatal(err)
+	} else if err = bed.AllowPlayer(ctx, PlayerAllowList{Name: "steve", IgnoreLimits: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	players := map[string][]logs.Player{
+		"Sirherobrine23": {&bedrocklog.BedrockPlayer{Username: "Sirherobrine23", Actioned: logs.Connect, PlayerXUID: 2535413418839840}},
+	}
+	if err := bed.FillAllowListXUID(ctx, players); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := bed.AllowList()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(list) != 2 {
+		t.Fatalf("expected 2 players: %+v", list)
+	} else if player, _ := list.Get("Sirherobrine23"); player.XUID != "2535413418839840" {
+		t.Errorf("xuid not filled: %+v", player)
+	} else if player, _ := list.Get("Steve"); player.XUID != "123" || !player.IgnoreLimits {
+		t.Errorf("player not updated: %+v", player)
+	}
+	if err = bed.DisallowPlayer(ctx, "STEVE"); err != nil {
+		t.Error(err)
+	} else if err = bed.DisallowPlayer(ctx, "Steve"); !errors.Is(err, logs.ErrPlayerNotExist) {
+		t.Errorf("expected player not exists: %v", err)
+	}
+
+	if err = bed.SetPermission(ctx, "2535413418839840", Operator); err != nil {
+		t.Fatal(err)
+	} else if err = bed.SetPermission(ctx, "123", Member); err != nil {
+		t.Fatal(err)
+	} else if err = bed.RemovePermission(ctx, "123"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(bed.ServerStart.Cwd, "permissions.json"))
+	if permissions, err := bed.Permissions(); err != nil {
+		t.Fatal(err)
+	} else if level, ok := permissions.Get("2535413418839840"); len(permissions) != 1 || !ok || level != Operator {
+		t.Errorf("invalid permissions.json: %s", data)
+	} else if !strings.Contains(string(data), `"operator"`) {
+		t.Errorf("permission not writed as text: %s", data)
+	}
+}
+
+func TestSaveQuery(t *testing.T) {
+	lines := []string{
+		"Data saved. Files are now ready to be copied.",
+		"Bedrock level/db/000005.ldb:10, Bedrock level/db/CURRENT:16, Bedrock level/level.dat:4",
+	}
+	files, ready, err := ParseSaveQuery(lines)
+	if err != nil {
+		t.Fatal(err)
+	} else if !ready || len(files) != 3 || files[0] != (SaveFile{Path: "Bedrock level/db/000005.ldb", Size: 10}) {
+		t.Fatalf("invalid files: %+v", files)
+	}
+	if _, ready, err = ParseSaveQuery([]string{"A previous save has not been completed."}); ready || err != nil {
+		t.Errorf("files not ready: %v, %v", ready, err)
+	}
+
+	// Copy truncated files
+	cwd := t.TempDir()
+	for _, file := range files {
+		name := filepath.Join(cwd, "worlds", filepath.FromSlash(file.Path))
+		os.MkdirAll(filepath.Dir(name), 0755)
+		if err = os.WriteFile(name, bytes.Repeat([]byte{'x'}, int(file.Size)+20), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buff bytes.Buffer
+	tarball := tar.NewWriter(&buff)
+	for _, file := range files {
+		err = copySaveFile(cwd, file, func(name string, info os.FileInfo, size int64) (io.Writer, error) {
+			return tarball, tarball.WriteHeader(&tar.Header{Name: name, Size: size, Mode: 0644})
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarball.Close()
+
+	reader := tar.NewReader(&buff)
+	for _, file := range files {
+		header, err := reader.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if header.Name != "worlds/"+file.Path || header.Size != file.Size {
+			t.Errorf("invalid entry %q with %d bytes", header.Name, header.Size)
+		}
+	}
+
+	err = copySaveFile(cwd, SaveFile{Path: "Bedrock level/level.dat", Size: 100}, nil)
+	if !errors.Is(err, ErrSaveFile) {
+		t.Errorf("expected file smaller error: %v", err)
+	}
+}
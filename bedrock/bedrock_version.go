@@ -5,6 +5,7 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
@@ -19,6 +20,7 @@ import (
 	"time"
 
 	"sirherobrine23.com.br/go-bds/go-bds/binfmt"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/js_types"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/v2"
@@ -74,7 +76,7 @@ type MojangApiVersion struct {
 	Version string
 }
 
-func (versions *Versions) versionProcess(newVersions <-chan MojangApiVersion, errChan chan<- error, wait *sync.WaitGroup) {
+func (versions *Versions) versionProcess(newVersions <-chan MojangApiVersion, errChan chan<- error, locker *sync.Mutex, wait *sync.WaitGroup) {
 	defer wait.Done() // Workder done after newVersions close
 	for value := range newVersions {
 		zipFile, _, err := request.SaveTmp(value.URL, "", &request.Options{Method: "GET", Header: MojangHeaders})
@@ -141,14 +143,16 @@ func (versions *Versions) versionProcess(newVersions <-chan MojangApiVersion, er
 
 		zipFile.Close()
 		os.Remove(zipFile.Name())
+		locker.Lock()
 		if version, _ := versions.Get(value.Version); version.Plaforms[platform] == nil {
 			version.Plaforms[platform] = platformVersion
 		}
+		locker.Unlock()
 	}
 }
 
 // Fetch versions from minecraft.net and append to [*Versions] if not exists.
-// This make SHA1 to ZIP file and get server release Date/time
+// This make SHA1 to ZIP file and get server release Date/time, zip files already in versions are not downloaded again
 func (versions *Versions) FetchFromMinecraftDotNet() error {
 	linkers, _, err := request.JSON[struct {
 		Result struct {
@@ -164,9 +168,10 @@ func (versions *Versions) FetchFromMinecraftDotNet() error {
 	errChan := make(chan error, len(pageVersions)-1)
 
 	var wait sync.WaitGroup
+	var locker sync.Mutex
 	for range runtime.NumCPU() {
 		wait.Add(1)
-		go versions.versionProcess(newVersions, errChan, &wait)
+		go versions.versionProcess(newVersions, errChan, &locker, &wait)
 	}
 
 	for _, value := range pageVersions {
@@ -177,7 +182,9 @@ func (versions *Versions) FetchFromMinecraftDotNet() error {
 		// Get server version from file server
 		// example: bedrock-server-1.6.1.0.zip => 1.6.1.0
 		value.Version = strings.TrimSuffix(strings.TrimPrefix(path.Base(value.URL), "bedrock-server-"), ".zip")
-		if _, exist := versions.Get(value.Version); exist == ErrNoVersion { // Create version to slice
+		locker.Lock()
+		version, exist := versions.Get(value.Version)
+		if exist == ErrNoVersion { // Create version to slice
 			*versions = append(*versions, &Version{
 				Version:   value.Version,
 				IsPreview: strings.Contains(value.Type, "Preview"),
@@ -185,7 +192,11 @@ func (versions *Versions) FetchFromMinecraftDotNet() error {
 				Plaforms:  map[string]*PlatformVersion{},
 			})
 		}
-		newVersions <- value // Add to worker
+		processed := exist == nil && version.hasZip(value.URL)
+		locker.Unlock()
+		if !processed {
+			newVersions <- value // Add to worker
+		}
 	}
 
 	// Close channel and wait to worker's done
@@ -200,6 +211,16 @@ func (versions *Versions) FetchFromMinecraftDotNet() error {
 	return nil
 }
 
+// Zip url already in version platforms
+func (version *Version) hasZip(zipURL string) bool {
+	for _, platform := range version.Plaforms {
+		if platform.ZipFile == zipURL {
+			return true
+		}
+	}
+	return false
+}
+
 // File target to <os>/<arch>
 type PlatformVersion struct {
 	ReleaseDate time.Time `json:"releaseDate"` // Platform release/build day
@@ -209,43 +230,31 @@ type PlatformVersion struct {
 	TarSHA1     string    `json:"tarSHA1"`     // SHA1 to verify integrety to tar file
 }
 
-// Download server file and check file SHA1
-func (target PlatformVersion) Download(w io.Writer) error {
+// Download server file and check file SHA1, progress can be nil
+func (target PlatformVersion) Download(ctx context.Context, w io.Writer, progress cache.Progress) error {
 	downloadUrl, fileSHA1 := target.ZipFile, target.ZipSHA1
 	if target.TarFile != "" {
 		downloadUrl = target.TarFile
 		fileSHA1 = target.TarSHA1
 	}
 
-	// Request server file
-	response, err := request.Request(downloadUrl, &request.Options{Method: "GET", Header: MojangHeaders})
+	// Server file from cache, SHA1 is checked if exists
+	file, err := cache.Default.Open(ctx, downloadUrl, cache.Hash{Algorithm: cache.SHA1, Sum: fileSHA1}, &request.Options{Method: "GET", Header: MojangHeaders}, progress)
 	if err != nil {
 		return err
 	}
-	defer response.Body.Close()
-
-	// Dont check file SHA1
-	if fileSHA1 == "" {
-		_, err = io.Copy(w, response.Body)
-		return err
-	}
-
-	sha1Sum := sha1.New()
-	if _, err = io.Copy(io.MultiWriter(sha1Sum, w), response.Body); err != nil {
-		return err
-	} else if hex.EncodeToString(sha1Sum.Sum(nil)) != fileSHA1 {
-		return errors.New("invalid file dowloaded")
-	}
-	return nil
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
 }
 
-// Extract server to folder path
-func (target PlatformVersion) Extract(cwd string) error {
+// Extract server to folder path, canceled extract not leave files in cwd
+func (target PlatformVersion) Extract(ctx context.Context, cwd string, progress cache.Progress) error {
 	switch {
 	case target.TarFile != "":
-		return request.Tar(target.TarFile, request.ExtractOptions{Cwd: cwd}, nil)
+		return cache.Default.Tar(ctx, target.TarFile, cache.Hash{Algorithm: cache.SHA1, Sum: target.TarSHA1}, request.ExtractOptions{Cwd: cwd}, nil, progress)
 	case target.ZipFile != "":
-		return request.Zip(target.ZipFile, request.ExtractOptions{Cwd: cwd}, &request.Options{Method: "GET", Header: MojangHeaders})
+		return cache.Default.Zip(ctx, target.ZipFile, cache.Hash{Algorithm: cache.SHA1, Sum: target.ZipSHA1}, request.ExtractOptions{Cwd: cwd}, &request.Options{Method: "GET", Header: MojangHeaders}, progress)
 	default:
 		return errors.New("cannot extract server target")
 	}
@@ -0,0 +1,178 @@
+// RakNet unconnected ping to get Bedrock, Pocketmine and AllayMC server status
+//
+// Protocol reference: https://wiki.vg/Raknet_Protocol#Unconnected_Ping
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+)
+
+const (
+	DefaultPort = 19132 // Default Bedrock IPv4 port
+
+	idUnconnectedPing byte = 0x01
+	idUnconnectedPong byte = 0x1C
+)
+
+var (
+	ErrPacket error = errors.New("raknet: invalid pong packet") // Server response not is unconnected pong
+	ErrStatus error = errors.New("raknet: invalid status")      // Status string not is MCPE or MCEE
+	ErrNoPort error = errors.New("raknet: no ports to probe")   // Ports list is empty
+
+	DefaultTimeout = 5 * time.Second // Default time to wait server response if ctx not have deadline
+
+	// RakNet offline message data id
+	Magic = [16]byte{0x00, 0xFF, 0xFF, 0x00, 0xFE, 0xFE, 0xFE, 0xFE, 0xFD, 0xFD, 0xFD, 0xFD, 0x12, 0x34, 0x56, 0x78}
+)
+
+// Server status from unconnected pong
+type Status struct {
+	Edition    string        `json:"edition"`      // MCPE or MCEE (Education Edition)
+	MOTD       string        `json:"motd"`         // Server name
+	Protocol   int           `json:"protocol"`     // Protocol version
+	Version    string        `json:"version"`      // Game version, example "1.21.70"
+	Online     int           `json:"online"`       // Players online
+	Max        int           `json:"max"`          // Max players
+	ServerGUID string        `json:"server_guid"`  // Server unique id
+	LevelName  string        `json:"level_name"`   // World name
+	GameMode   string        `json:"game_mode"`    // Game mode name, example "Survival"
+	GameModeID int           `json:"game_mode_id"` // Game mode number
+	PortV4     int           `json:"port_v4"`      // IPv4 port
+	PortV6     int           `json:"port_v6"`      // IPv6 port
+	Latency    time.Duration `json:"latency"`      // Ping latency
+	Raw        string        `json:"-"`            // Status string
+}
+
+// Parse MCPE status string
+//
+//	"MCPE;Dedicated Server;766;1.21.50;0;10;13253860892328930865;Bedrock level;Survival;1;19132;19133;"
+func ParseStatus(text string) (*Status, error) {
+	fields := strings.Split(text, ";")
+	if len(fields) < 6 || (fields[0] != "MCPE" && fields[0] != "MCEE") {
+		return nil, fmt.Errorf("%w: %q", ErrStatus, text)
+	}
+
+	field := func(index int) string {
+		if index < len(fields) {
+			return fields[index]
+		}
+		return ""
+	}
+
+	status := &Status{
+		Edition:    fields[0],
+		MOTD:       fields[1],
+		Version:    fields[3],
+		ServerGUID: field(6),
+		LevelName:  field(7),
+		GameMode:   field(8),
+		Raw:        text,
+	}
+	status.Protocol, _ = strconv.Atoi(fields[2])
+	status.Online, _ = strconv.Atoi(fields[4])
+	status.Max, _ = strconv.Atoi(fields[5])
+	status.GameModeID, _ = strconv.Atoi(field(9))
+	status.PortV4, _ = strconv.Atoi(field(10))
+	status.PortV6, _ = strconv.Atoi(field(11))
+	return status, nil
+}
+
+// Send unconnected ping and return server status, address port is optional and default is [DefaultPort]
+func Ping(ctx context.Context, address string) (*Status, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(strings.Trim(address, "[]"), strconv.Itoa(DefaultPort))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	started := time.Now()
+	request := bytes.NewBuffer([]byte{idUnconnectedPing})
+	binary.Write(request, binary.BigEndian, started.UnixMilli())
+	request.Write(Magic[:])
+	binary.Write(request, binary.BigEndian, rand.Int64())
+	if _, err = conn.Write(request.Bytes()); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, 1500)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		// id(1) + time(8) + server guid(8) + magic(16) + string size(2)
+		packet := response[:n]
+		if len(packet) < 35 || packet[0] != idUnconnectedPong || !bytes.Equal(packet[17:33], Magic[:]) {
+			continue
+		}
+		size := int(binary.BigEndian.Uint16(packet[33:35]))
+		if 35+size > len(packet) {
+			return nil, ErrPacket
+		}
+
+		status, err := ParseStatus(string(packet[35 : 35+size]))
+		if err != nil {
+			return nil, err
+		}
+		status.Latency = time.Since(started)
+		return status, nil
+	}
+}
+
+// Health probe to ports reported by logs parse, unspecified address
+// like 0.0.0.0 is replaced by localhost. Return status from first port that reply
+func Probe(ctx context.Context, ports []*logs.Port) (*Status, error) {
+	errs := []error{}
+	for _, port := range ports {
+		if port == nil || !port.AddrPort.IsValid() {
+			continue
+		}
+
+		addr := port.AddrPort.Addr()
+		if addr.IsUnspecified() && addr.Is6() {
+			addr = netip.IPv6Loopback()
+		} else if addr.IsUnspecified() {
+			addr = netip.AddrFrom4([4]byte{127, 0, 0, 1})
+		}
+
+		status, err := Ping(ctx, netip.AddrPortFrom(addr, port.AddrPort.Port()).String())
+		if err == nil {
+			return status, nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil, ErrNoPort
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+)
+
+const statusText = "MCPE;Dedicated Server;766;1.21.50;2;10;13253860892328930865;Bedrock level;Survival;1;19132;19133;"
+
+// Fake RakNet server reply unconnected ping
+func fakeServer(t *testing.T) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buff := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buff)
+			if err != nil {
+				return
+			} else if n < 33 || buff[0] != idUnconnectedPing {
+				continue
+			}
+
+			response := bytes.NewBuffer([]byte{idUnconnectedPong})
+			response.Write(buff[1:9]) // Ping time
+			binary.Write(response, binary.BigEndian, int64(1325386089))
+			response.Write(Magic[:])
+			binary.Write(response, binary.BigEndian, uint16(len(statusText)))
+			response.WriteString(statusText)
+			conn.WriteTo(response.Bytes(), addr)
+		}
+	}()
+	return conn
+}
+
+func TestPing(t *testing.T) {
+	conn := fakeServer(t)
+	status, err := Ping(context.Background(), conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	} else if status.MOTD != "Dedicated Server" || status.Version != "1.21.50" || status.Protocol != 766 {
+		t.Errorf("invalid status: %+v", status)
+	} else if status.Online != 2 || status.Max != 10 || status.PortV4 != 19132 || status.PortV6 != 19133 || status.GameMode != "Survival" {
+		t.Errorf("invalid status: %+v", status)
+	}
+
+	// Probe with port reported by logs
+	port := uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	if _, err = Probe(context.Background(), []*logs.Port{{AddrPort: netip.AddrPortFrom(netip.IPv4Unspecified(), port), From: "server"}}); err != nil {
+		t.Error(err)
+	}
+
+	if _, err = ParseStatus("MCJE;invalid"); err == nil {
+		t.Error("parsed invalid status")
+	}
+}
@@ -1,6 +1,7 @@
 package pmmp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/js_types"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/regex"
@@ -71,14 +73,14 @@ func gitRepo(repoPath, repoUrl string) (*git.Repository, error) {
 // Return semver version from PHP
 func (ver PHP) SemverVersion() semver.Version { return semver.New(ver.PHPVersion) }
 
-// Install prebuild binary's
-func (php PHP) Install(installPath string) error {
+// Install prebuild binary's, canceled install not leave files in installPath
+func (php PHP) Install(ctx context.Context, installPath string, progress cache.Progress) error {
 	if urlDownload, ok := php.Downloads[fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH)]; ok {
 		switch path.Ext(urlDownload) {
 		case ".zip":
-			return request.Zip(urlDownload, request.ExtractOptions{Cwd: installPath}, nil)
+			return cache.Default.Zip(ctx, urlDownload, cache.Hash{}, request.ExtractOptions{Cwd: installPath}, nil, progress)
 		default:
-			return request.Tar(urlDownload, request.ExtractOptions{Cwd: installPath}, nil)
+			return cache.Default.Tar(ctx, urlDownload, cache.Hash{}, request.ExtractOptions{Cwd: installPath}, nil, progress)
 		}
 	}
 	return fmt.Errorf("prebuild to %s/%s not exists", runtime.GOOS, runtime.GOARCH)
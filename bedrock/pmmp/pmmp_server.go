@@ -9,12 +9,18 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
 	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 	"sirherobrine23.com.br/go-bds/overlayfs"
 )
 
+var _ server.Server = &Pocketmine{}
+
 // Create and setup basics info to start Pocketmine-PMMP
 func NewPocketmine(version *Version, versionFolder, cwd, upper, workdir string) (*Pocketmine, error) {
 	if version == nil {
@@ -35,14 +41,14 @@ func NewPocketmine(version *Version, versionFolder, cwd, upper, workdir string)
 	switch runtime.GOOS {
 	case "windows":
 		if !file_checker.IsFile(filepath.Join(phpFolder, "bin/php/php.exe")) {
-			if err := version.PHP.Install(phpFolder); err != nil {
+			if err := version.PHP.Install(context.Background(), phpFolder, nil); err != nil {
 				return nil, err
 			}
 		}
 		phpFolder = filepath.Join(phpFolder, "bin/php/php.exe")
 	default:
 		if !file_checker.IsFile(filepath.Join(phpFolder, "bin/php")) {
-			if err := version.PHP.Install(phpFolder); err != nil {
+			if err := version.PHP.Install(context.Background(), phpFolder, nil); err != nil {
 				return nil, err
 			}
 		}
@@ -91,8 +97,11 @@ func NewPocketmine(version *Version, versionFolder, cwd, upper, workdir string)
 type Pocketmine struct {
 	PID         exec.Proc            // process status
 	ServerStart exec.ProcExec        // Server command
+	StopConfig  server.StopOptions   // Stop stages config
 	Version     *Version             // Server version
 	Overlayfs   *overlayfs.Overlayfs // Overlayfs mounted
+
+	restored *server.RestorePoint // Files replaced by Restore
 }
 
 // Make server backup with [*archive/tar.Writer]
@@ -121,11 +130,15 @@ func (pmmp Pocketmine) Zip(w io.Writer) error {
 	return wr.AddFS(os.DirFS(pmmp.Overlayfs.Upper))
 }
 
-func (pmmp *Pocketmine) Start(ctx context.Context) error {
+// Start server
+func (pmmp *Pocketmine) Start(ctx context.Context) (err error) {
 	if pmmp == nil || pmmp.PID == nil {
 		return errors.New("cannot start server, server proc not defined")
 	}
 
+	// Keep or rollback restored files
+	defer func() { err = pmmp.restored.Started(err, pmmp.PID, &javalog.JavaParse{}) }()
+
 	// If overlayfs configured mount before start server
 	if pmmp.Overlayfs != nil {
 		if err := pmmp.Overlayfs.Mount(ctx); err != nil {
@@ -134,8 +147,69 @@ func (pmmp *Pocketmine) Start(ctx context.Context) error {
 	}
 
 	// Start server
-	if err := pmmp.PID.Start(pmmp.ServerStart); err != nil {
+	return pmmp.PID.Start(pmmp.ServerStart)
+}
+
+// Restore backup from [Pocketmine.Tar] or [Pocketmine.Zip], server must be stopped.
+//
+// If server mounted with [*sirherobrine23.com.br/go-bds/go-bds/overlayfs.Overlayfs] restore to Upper layer else to server folder,
+// old files are kept until restored server start, if start fail or server exit before old files are restored
+func (pmmp *Pocketmine) Restore(r io.Reader, format server.ArchiveFormat) error {
+	if pmmp == nil {
+		return server.ErrNoProc
+	} else if server.Running(pmmp.PID) {
+		return server.ErrRunning
+	}
+
+	target := pmmp.ServerStart.Cwd
+	if pmmp.Overlayfs != nil {
+		target = pmmp.Overlayfs.Upper
+	}
+	point, err := server.RestoreArchive(r, format, target, server.LayoutAny("server.properties", "pocketmine.yml", "worlds"))
+	if err != nil {
 		return err
 	}
+	pmmp.restored.Wait()
+	pmmp.restored.Commit() // Previous restore not started
+	pmmp.restored = point
 	return nil
 }
+
+// Stop server with StopConfig stages ("stop" command, SIGINT and kill),
+// return stage that ended server and exit code
+func (pmmp *Pocketmine) Stop(ctx context.Context) (*server.StopStatus, error) {
+	if pmmp == nil || pmmp.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.Stop(ctx, pmmp.PID, pmmp.StopConfig)
+}
+
+// Write command to server console and return lines printed in response
+func (pmmp *Pocketmine) RunCommand(ctx context.Context, command string) ([]string, error) {
+	if pmmp == nil || pmmp.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.RunCommand(ctx, pmmp.PID, command, server.CommandOptions{ParseLine: javalog.ParseLine, Terminator: commandEnd})
+}
+
+// Pocketmine print "Unknown command" on invalid command
+func commandEnd(line *logs.Line) bool { return strings.HasPrefix(line.Message, "Unknown command") }
+
+// Wait server process end
+func (pmmp *Pocketmine) Wait() error {
+	if pmmp == nil || pmmp.PID == nil {
+		return server.ErrNoProc
+	}
+	return pmmp.PID.Wait()
+}
+
+// Server process
+func (pmmp *Pocketmine) Proc() exec.Proc { return pmmp.PID }
+
+// Server version
+func (pmmp *Pocketmine) ServerVersion() string {
+	if pmmp.Version == nil {
+		return ""
+	}
+	return pmmp.Version.Version
+}
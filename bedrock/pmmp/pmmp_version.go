@@ -1,6 +1,7 @@
 package pmmp
 
 import (
+	"context"
 	"encoding/json"
 	"iter"
 	"path"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/github"
 	"sirherobrine23.com.br/go-bds/request/v2"
@@ -27,8 +29,7 @@ func (ver Version) SemverVersion() semver.Version { return semver.New(ver.Versio
 
 func (ver Version) Download(path string) error {
 	if ver.Phar != "" {
-		_, err := request.SaveAs(ver.Phar, path, nil)
-		return err
+		return cache.Default.SaveAs(context.Background(), ver.Phar, cache.Hash{}, path, nil, nil)
 	}
 	return ErrNoVersion
 }
@@ -37,10 +38,9 @@ func (ver Version) Download(path string) error {
 type Versions []*Version
 
 // List all releases and PHP prebuilds from Github Releases to
-// PocketMine/PocketMine-MP and pmmp/PocketMine-MP
+// PocketMine/PocketMine-MP and pmmp/PocketMine-MP, versions is replaced only if all releases are fetched
 func (versions *Versions) GetVersionsFromGithub(phpBuilds PHPs) error {
-	// Clean versions
-	*versions = (*versions)[:0]
+	fetched := Versions{}
 
 	// Pocketmine repo
 	client := github.NewClient("pmmp", "PocketMine-MP", "")
@@ -100,15 +100,16 @@ func (versions *Versions) GetVersionsFromGithub(phpBuilds PHPs) error {
 			}
 
 			if newVersion.Phar != "" {
-				*versions = append(*versions, newVersion)
+				fetched = append(fetched, newVersion)
 			}
 		}
 	}
 
-	slices.SortFunc(*versions, func(a, b *Version) int {
+	slices.SortFunc(fetched, func(a, b *Version) int {
 		return a.Release.Compare(b.Release)
 	})
 
+	*versions = fetched
 	return nil
 }
 
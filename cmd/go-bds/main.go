@@ -0,0 +1,391 @@
+// Install, run and backup Minecraft servers from command line,
+// instances and versions are saved in data folder
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"sirherobrine23.com.br/go-bds/go-bds/manager"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
+)
+
+var (
+	DataDir = flag.String("data", defaultDataDir(), "Folder to save instances, versions and java installs, env GO_BDS_DATA")
+	Offline = flag.Bool("offline", false, "Only use versions saved in catalog, never fetch upstream")
+	MaxSize = flag.Int64("cache-size", 0, "Download cache limit in MiB, 0 is unlimited")
+	Seed    = flag.String("seed", "", "Folder with pre-downloaded server files, used before download")
+)
+
+const usage = `Usage: go-bds [-data folder] [-offline] [-cache-size MiB] [-seed folder] <command> [options]
+
+Commands:
+  versions list [-platform bedrock]         list versions to platform
+  list                                      list instances
+  install [-platform] [-version] <name>     create instance and install server
+  run <name>                                start server with console attached
+  backup [-format tar] [-o file] <name>     write backup to file or stdout
+  restore [-format tar] <name> <file>       restore backup, server must be stopped
+  properties get <name> [key...]            print server.properties keys
+  properties set <name> <key=value...>      set server.properties keys
+  java install <version>                    install java, example 21
+
+Platforms: %s
+`
+
+func defaultDataDir() string {
+	if dir := os.Getenv("GO_BDS_DATA"); dir != "" {
+		return dir
+	} else if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "go-bds")
+	}
+	return filepath.Join(os.TempDir(), "go-bds")
+}
+
+func main() {
+	flag.Usage = func() {
+		platforms := []string{}
+		for _, platform := range manager.Platforms {
+			platforms = append(platforms, string(platform))
+		}
+		fmt.Fprintf(os.Stderr, usage, strings.Join(platforms, ", "))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cache.Default = &cache.Cache{Folder: filepath.Join(*DataDir, "cache"), MaxSize: *MaxSize << 20}
+	if *Seed != "" {
+		cache.Default.Seed = []string{*Seed}
+	}
+
+	var err error
+	args := flag.Args()
+	switch args[0] {
+	case "versions":
+		err = versionsCmd(args[1:])
+	case "list":
+		err = listCmd()
+	case "install":
+		err = installCmd(args[1:])
+	case "run":
+		err = runCmd(args[1:])
+	case "backup":
+		err = backupCmd(args[1:])
+	case "restore":
+		err = restoreCmd(args[1:])
+	case "properties":
+		err = propertiesCmd(args[1:])
+	case "java":
+		err = javaCmd(args[1:])
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "go-bds %s: %s\n", args[0], err)
+		os.Exit(1)
+	}
+}
+
+var remoteVersions = &manager.RemoteVersions{}
+
+// Set versions folders in data folder
+func setupVersions() {
+	remoteVersions.PHPScripts = filepath.Join(*DataDir, "php-scripts")
+	remoteVersions.Catalog = &manager.Catalog{Folder: filepath.Join(*DataDir, "catalog"), Offline: *Offline}
+}
+
+// Open manager in data folder
+func openManager() (*manager.Manager, error) {
+	setupVersions()
+	return manager.Open(filepath.Join(*DataDir, "instances.json"), remoteVersions)
+}
+
+// Parse subcommand flags and check arguments count
+func parseArgs(set *flag.FlagSet, args []string, minArgs int, usage string) ([]string, error) {
+	if err := set.Parse(args); err != nil {
+		return nil, err
+	} else if set.NArg() < minArgs {
+		return nil, fmt.Errorf("usage: go-bds %s", usage)
+	}
+	return set.Args(), nil
+}
+
+func versionsCmd(args []string) error {
+	if len(args) == 0 || args[0] != "list" {
+		return errors.New("usage: go-bds versions list [-platform bedrock]")
+	}
+	set := flag.NewFlagSet("versions list", flag.ExitOnError)
+	platform := set.String("platform", string(manager.Bedrock), "Server platform")
+	if _, err := parseArgs(set, args[1:], 0, "versions list [-platform bedrock]"); err != nil {
+		return err
+	} else if !slices.Contains(manager.Platforms, manager.Platform(*platform)) {
+		return fmt.Errorf("%w: %q", manager.ErrPlatform, *platform)
+	}
+
+	setupVersions()
+	names, err := remoteVersions.Names(manager.Platform(*platform))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+func listCmd() error {
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tPLATFORM\tVERSION\tSTATUS\tPORTS")
+	for _, instance := range manage.List() {
+		status, ports := "stopped", []string{}
+		if manage.Running(instance.Name) {
+			status = "running"
+		}
+		for _, port := range instance.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Network))
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", instance.Name, instance.Platform, instance.Version, status, strings.Join(ports, ","))
+	}
+	return table.Flush()
+}
+
+func installCmd(args []string) error {
+	set := flag.NewFlagSet("install", flag.ExitOnError)
+	platform := set.String("platform", string(manager.Bedrock), "Server platform")
+	version := set.String("version", manager.Latest, "Server version")
+	backend := set.String("backend", string(manager.BackendOs), "Process backend, os, proot or docker")
+	args, err := parseArgs(set, args, 1, "install [-platform bedrock] [-version latest] [-backend os] <name>")
+	if err != nil {
+		return err
+	}
+
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+	if _, err = manage.Get(name); errors.Is(err, manager.ErrNotFound) {
+		err = manage.Create(manager.Instance{
+			Name:          name,
+			Platform:      manager.Platform(*platform),
+			Version:       *version,
+			Backend:       manager.Backend(*backend),
+			VersionFolder: filepath.Join(*DataDir, "versions", *platform),
+			JavaFolder:    filepath.Join(*DataDir, "java"),
+			Cwd:           filepath.Join(*DataDir, "servers", name),
+			Upper:         filepath.Join(*DataDir, "overlay", name, "upper"),
+			Workdir:       filepath.Join(*DataDir, "overlay", name, "work"),
+		})
+	} else if err == nil && *version != manager.Latest {
+		err = manage.Update(name, func(instance *manager.Instance) error { instance.Version = *version; return nil })
+	}
+	if err != nil {
+		return err
+	}
+
+	srv, err := manage.Install(name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s installed with version %s\n", name, srv.ServerVersion())
+	return nil
+}
+
+func runCmd(args []string) error {
+	set := flag.NewFlagSet("run", flag.ExitOnError)
+	args, err := parseArgs(set, args, 1, "run <name>")
+	if err != nil {
+		return err
+	}
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+	name := args[0]
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv, err := manage.Server(name)
+	if err != nil {
+		return err
+	}
+
+	// Attach console before start to print first lines, Docker logs are only streamed after start
+	proc := srv.Proc()
+	stdoutErr, stderrErr := proc.AppendToStdout(os.Stdout), proc.AppendToStderr(os.Stderr)
+	if err = manage.Start(ctx, name); err != nil {
+		return err
+	}
+	if stdoutErr != nil {
+		stdoutErr = attachFork(proc.StdoutFork, os.Stdout)
+	}
+	if stderrErr != nil {
+		stderrErr = attachFork(proc.StderrFork, os.Stderr)
+	}
+	if err := errors.Join(stdoutErr, stderrErr); err != nil {
+		fmt.Fprintf(os.Stderr, "cannot attach server output: %s\n", err)
+	}
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if _, err := proc.Write([]byte(scanner.Text() + "\n")); err != nil {
+				return
+			}
+		}
+	}()
+
+	exited := make(chan error, 1)
+	go func() { exited <- srv.Wait() }()
+	select {
+	case err = <-exited:
+		return err
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr, "stopping server")
+		status, err := manage.Stop(context.Background(), name)
+		if err == nil && status.ExitCode != 0 {
+			err = fmt.Errorf("server exit with code %d after %s", status.ExitCode, status.Stage)
+		}
+		return err
+	}
+}
+
+// Copy forked stream to w in background
+func attachFork(fork func() (io.ReadCloser, error), w io.Writer) error {
+	r, err := fork()
+	if err != nil {
+		return err
+	}
+	go io.Copy(w, r)
+	return nil
+}
+
+func backupCmd(args []string) error {
+	set := flag.NewFlagSet("backup", flag.ExitOnError)
+	format := set.String("format", string(server.FormatTar), "Archive format, tar or zip")
+	output := set.String("o", "", "Output file, default is stdout")
+	args, err := parseArgs(set, args, 1, "backup [-format tar] [-o file] <name>")
+	if err != nil {
+		return err
+	}
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return manage.Backup(context.Background(), args[0], w, server.ArchiveFormat(*format))
+}
+
+func restoreCmd(args []string) error {
+	set := flag.NewFlagSet("restore", flag.ExitOnError)
+	format := set.String("format", string(server.FormatTar), "Archive format, tar or zip")
+	args, err := parseArgs(set, args, 2, "restore [-format tar] <name> <file>")
+	if err != nil {
+		return err
+	}
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(args[1])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return manage.Restore(args[0], file, server.ArchiveFormat(*format))
+}
+
+func propertiesCmd(args []string) error {
+	if len(args) < 2 || (args[0] != "get" && args[0] != "set") {
+		return errors.New("usage: go-bds properties get <name> [key...] or properties set <name> <key=value...>")
+	}
+	manage, err := openManager()
+	if err != nil {
+		return err
+	}
+
+	name, keys := args[1], args[2:]
+	if args[0] == "set" {
+		values := map[string]string{}
+		for _, keyValue := range keys {
+			key, value, ok := strings.Cut(keyValue, "=")
+			if !ok {
+				return fmt.Errorf("invalid %q, use key=value", keyValue)
+			}
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		return manage.SetProperties(name, values)
+	}
+
+	values, err := manage.Properties(name)
+	if err != nil {
+		return err
+	} else if len(keys) == 0 {
+		keys = slices.Sorted(maps.Keys(values))
+	}
+	for _, key := range keys {
+		fmt.Printf("%s = %s\n", key, values[key])
+	}
+	return nil
+}
+
+func javaCmd(args []string) error {
+	if len(args) != 2 || args[0] != "install" {
+		return errors.New("usage: go-bds java install <version>")
+	}
+	var version javaprebuild.JavaVersion
+	if err := version.UnmarshalText([]byte("Java " + args[1])); err != nil {
+		return fmt.Errorf("invalid java version %q", args[1])
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	javaPath, err := version.Install(ctx, filepath.Join(*DataDir, "java", strconv.Itoa(int(version))), printProgress)
+	if err != nil {
+		return err
+	}
+	fmt.Println(javaPath)
+	return nil
+}
+
+// Print install progress to stderr
+func printProgress(phase cache.Phase, done, total int64) {
+	if total > 0 {
+		fmt.Fprintf(os.Stderr, "\r%s %d/%d", phase, done, total)
+		if done >= total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
@@ -168,6 +168,15 @@ func (docker *DockerContainer) ExitCode() (int, error) {
 	return int(docker.statusExit.StatusCode), nil
 }
 
+// Inspect container state, false if container not created or inspect fail
+func (docker *DockerContainer) Running() bool {
+	if docker.containerID == "" || docker.statusExit != nil {
+		return false
+	}
+	info, err := docker.DockerClient.ContainerInspect(context.Background(), docker.containerID)
+	return err == nil && info.State != nil && info.State.Running
+}
+
 func (docker *DockerContainer) Close() error {
 	if docker.containerID == "" {
 		return ErrNoRunning
@@ -272,6 +281,32 @@ func (docker *DockerContainer) Start(options ProcExec) error {
 	return nil
 }
 
+// Find running container with ContainerName and attach to it,
+// allow control container started by other process. Return false if container not running
+func (docker *DockerContainer) Reattach(ctx context.Context) (bool, error) {
+	if docker.ContainerName == "" {
+		return false, nil
+	} else if docker.containerID != "" {
+		return true, nil
+	} else if docker.DockerClient == nil {
+		var err error
+		if docker.DockerClient, err = client.NewClientWithOpts(client.FromEnv); err != nil {
+			return false, err
+		}
+	}
+
+	info, err := docker.DockerClient.ContainerInspect(ctx, docker.ContainerName)
+	if client.IsErrNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	} else if info.State == nil || !info.State.Running {
+		return false, nil
+	}
+	docker.containerID = info.ID
+	return true, nil
+}
+
 func (docker *DockerContainer) AppendToStdout(w io.Writer) error { return fs.ErrInvalid }
 func (docker *DockerContainer) AppendToStderr(w io.Writer) error { return fs.ErrInvalid }
 func (docker *DockerContainer) AppendToStdin(r io.Reader) error  { return fs.ErrInvalid }
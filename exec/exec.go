@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"sync"
 )
 
 var (
@@ -38,6 +40,7 @@ type Proc interface {
 	Wait() error                        // Wait process
 	Signal(s os.Signal) error           // Send signal to process
 	ExitCode() (int, error)             // return process exit code, if running wait to get exit code
+	Running() bool                      // Process started and not exited, not wait process
 	Write(p []byte) (int, error)        // Write to stdin
 	AppendToStdin(r io.Reader) error    // Add reader to stdin
 	AppendToStdout(w io.Writer) error   // Append writer to stdout
@@ -51,20 +54,28 @@ type Proc interface {
 type Writers struct {
 	Std    []io.Writer
 	Closed bool
+
+	locker sync.Mutex
 }
 
 func (p *Writers) AddNewWriter(w io.Writer) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
 	if !p.Closed {
 		p.Std = append(p.Std, w)
 	}
 }
 
 func (p *Writers) Close() error {
+	p.locker.Lock()
+	defer p.locker.Unlock()
 	p.Closed = true
 	return nil
 }
 
 func (p *Writers) Write(w []byte) (int, error) {
+	p.locker.Lock()
+	defer p.locker.Unlock()
 	if p.Closed {
 		return 0, io.EOF
 	}
@@ -76,25 +87,14 @@ func (p *Writers) Write(w []byte) (int, error) {
 		switch _, err := p.Std[indexWriter].Write(w); err {
 		case nil:
 			continue
-		case io.EOF, io.ErrUnexpectedEOF:
+		case io.EOF, io.ErrUnexpectedEOF, io.ErrClosedPipe:
 			p.Std[indexWriter] = nil
 		default:
 			return 0, err
 		}
 	}
 
-	for writeIndex := 0; writeIndex < len(p.Std); writeIndex++ {
-		if p.Std[writeIndex] != nil {
-			continue
-		} else if writeIndex == len(p.Std) {
-			p.Std = p.Std[writeIndex-1:]
-		} else if writeIndex == 0 {
-			p.Std = p.Std[1:]
-		} else {
-			p.Std = append(p.Std[writeIndex:], p.Std[writeIndex+1:]...)
-		}
-		writeIndex -= 2
-	}
-
+	// Remove closed writers
+	p.Std = slices.DeleteFunc(p.Std, func(w io.Writer) bool { return w == nil })
 	return len(w), nil
 }
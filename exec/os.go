@@ -39,6 +39,15 @@ type Os struct {
 
 	stdin          io.WriteCloser
 	stdout, stderr *Writers
+
+	wait *osWait // Last started process wait
+}
+
+// Wait state owned by one started process, restart create new value
+type osWait struct {
+	cmd  *exec.Cmd
+	done chan struct{} // Closed when process exit
+	err  error         // Process exit error, set before done closed
 }
 
 func (os *Os) Write(w []byte) (int, error) {
@@ -66,36 +75,55 @@ func (w *Os) Close() error {
 }
 
 func (w *Os) Signal(sig os.Signal) error {
+	if w.osProc == nil || w.osProc.Process == nil {
+		return ErrNoRunning
+	}
 	return w.osProc.Process.Signal(sig)
 }
 
+// Wait process exit, safe to call from many goroutines
 func (os *Os) Wait() error {
-	switch {
-	case os.osProc != nil && os.osProc.ProcessState != nil:
-		if !os.osProc.ProcessState.Success() {
-			return &exec.ExitError{ProcessState: os.osProc.ProcessState}
-		}
-		return nil
-	case os.osProc != nil && os.osProc.Process != nil:
-		return os.osProc.Wait()
-	default:
+	wait := os.wait
+	if wait == nil {
 		return ErrNoRunning
 	}
+	<-wait.done
+	return wait.err
 }
 
-func (os *Os) ExitCode() (int, error) {
-	switch {
-	case os.osProc != nil && os.osProc.ProcessState != nil:
-		return os.osProc.ProcessState.ExitCode(), nil
-	case os.osProc != nil && os.osProc.Process != nil:
-		state, err := os.osProc.Process.Wait()
-		if err != nil {
-			return -1, err
-		}
-		return state.ExitCode(), nil
+// Process ID, 0 if process not started
+func (os *Os) Pid() int {
+	if os.osProc == nil || os.osProc.Process == nil {
+		return 0
+	}
+	return os.osProc.Process.Pid
+}
+
+// Process started and wait goroutine not finished
+func (os *Os) Running() bool {
+	wait := os.wait
+	if wait == nil {
+		return false
+	}
+	select {
+	case <-wait.done:
+		return false
 	default:
+		return true
+	}
+}
+
+// Wait process exit and return exit code, if wait fail before process state return wait error
+func (os *Os) ExitCode() (int, error) {
+	wait := os.wait
+	if wait == nil {
 		return -1, ErrNoRunning
 	}
+	<-wait.done
+	if wait.cmd.ProcessState == nil {
+		return -1, wait.err
+	}
+	return wait.cmd.ProcessState.ExitCode(), nil
 }
 
 func (cli *Os) StdinFork() (io.WriteCloser, error) {
@@ -117,14 +145,14 @@ func (cli *Os) AppendToStdout(w io.Writer) error {
 	if cli.stdout == nil {
 		cli.stdout = &Writers{}
 	}
-	cli.stdout.Std = append(cli.stdout.Std, w)
+	cli.stdout.AddNewWriter(w)
 	return nil
 }
 func (cli *Os) AppendToStderr(w io.Writer) error {
 	if cli.stderr == nil {
 		cli.stderr = &Writers{}
 	}
-	cli.stderr.Std = append(cli.stderr.Std, w)
+	cli.stderr.AddNewWriter(w)
 	return nil
 }
 func (cli *Os) AppendToStdin(r io.Reader) error {
@@ -160,5 +188,13 @@ func (w *Os) Start(options ProcExec) error {
 	if err := w.osProc.Start(); err != nil {
 		return err
 	}
+
+	// Wait process in background to allow many calls to Wait
+	wait := &osWait{cmd: w.osProc, done: make(chan struct{})}
+	w.wait = wait
+	go func() {
+		wait.err = wait.cmd.Wait()
+		close(wait.done)
+	}()
 	return nil
 }
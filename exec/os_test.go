@@ -19,5 +19,16 @@ func TestOs(t *testing.T) {
 	} else if err = sysProc.Wait(); err != nil {
 		t.Error(err)
 		return
+	} else if sysProc.Running() {
+		t.Error("process running after wait")
+	}
+
+	// Restart before last process exit
+	if err = sysProc.Start(ProcExec{Arguments: []string{goPath, "version"}}); err != nil {
+		t.Fatal(err)
+	} else if err = sysProc.Start(ProcExec{Arguments: []string{goPath, "version"}}); err != nil {
+		t.Fatal(err)
+	} else if code, err := sysProc.ExitCode(); err != nil || code != 0 {
+		t.Errorf("invalid exit code %d: %v", code, err)
 	}
 }
@@ -1,6 +1,7 @@
 package exec
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/netip"
@@ -8,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/v2"
 )
@@ -161,7 +163,7 @@ func (proc Proot) DownloadUbuntuRootfs(Version, Arch string) error {
 			Cwd:   proc.Rootfs,
 			Strip: 1,
 		}
-		return request.Tar(selectedArch.ChrootURL, extract, nil)
+		return cache.Default.Tar(context.Background(), selectedArch.ChrootURL, cache.Hash{}, extract, nil, nil)
 	}
 	return ErrNoExtractUbuntu
 }
@@ -0,0 +1,186 @@
+package java
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+var (
+	ErrSaveAll error = errors.New("server not printed \"Saved the game\"") // save-all flush not finished before timeout
+
+	SaveAllTimeout = 5 * time.Minute  // Max time to wait "Saved the game" after save-all flush
+	SaveOnTimeout  = 30 * time.Second // Max time to "save-on" after backup
+)
+
+// Files to hot backup
+type BackupOptions struct {
+	WorldsOnly bool     // Archive only worlds folders (level-name, level-name_nether and level-name_the_end)
+	Include    []string // Files or folders relative to server folder added with WorldsOnly, example "plugins", "config" or "server.properties"
+}
+
+// Hot backup result
+type BackupReport struct {
+	Files   []string `json:"files"`   // Files archived
+	Changed []string `json:"changed"` // Files modified while archiving, copy can be inconsistent
+}
+
+// Make server backup with [*archive/tar.Writer], if server running disable auto save
+// with "save-off", run "save-all flush" and always "save-on" after backup
+func (javaServer *Server) HotTar(ctx context.Context, w io.Writer, options BackupOptions) (*BackupReport, error) {
+	tarball := tar.NewWriter(w)
+	return javaServer.hotBackup(ctx, options, tarball.Close, func(name string, info fs.FileInfo) (io.Writer, error) {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return nil, err
+		}
+		header.Name = name
+		return tarball, tarball.WriteHeader(header)
+	})
+}
+
+// Make server backup with [*archive/zip.Writer], if server running disable auto save
+// with "save-off", run "save-all flush" and always "save-on" after backup
+func (javaServer *Server) HotZip(ctx context.Context, w io.Writer, options BackupOptions) (*BackupReport, error) {
+	wr := zip.NewWriter(w)
+	return javaServer.hotBackup(ctx, options, wr.Close, func(name string, info fs.FileInfo) (io.Writer, error) {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return nil, err
+		}
+		header.Name, header.Method = name, zip.Deflate
+		return wr.CreateHeader(header)
+	})
+}
+
+// Disable auto save and wait server write all chunks
+func (javaServer *Server) SaveOff(ctx context.Context) error {
+	if _, err := javaServer.RunCommand(ctx, "save-off"); err != nil {
+		return err
+	}
+
+	lines, sent, err := javaServer.rconCommand(ctx, "save-all flush")
+	if err != nil && !sent && javaServer.PID != nil && ctx.Err() == nil {
+		lines, err = server.RunCommand(ctx, javaServer.PID, "save-all flush", server.CommandOptions{
+			Wait:       SaveAllTimeout,
+			Quiet:      SaveAllTimeout,
+			ParseLine:  javalog.ParseLine,
+			Terminator: savedGame,
+		})
+	}
+	if err != nil {
+		return err
+	} else if !slices.ContainsFunc(lines, func(line string) bool { return savedGame(&logs.Line{Message: line}) }) {
+		return ErrSaveAll
+	}
+	return nil
+}
+
+// Enable auto save
+func (javaServer *Server) SaveOn(ctx context.Context) error {
+	_, err := javaServer.RunCommand(ctx, "save-on")
+	return err
+}
+
+func savedGame(line *logs.Line) bool { return strings.Contains(line.Message, "Saved the game") }
+
+func (javaServer *Server) hotBackup(ctx context.Context, options BackupOptions, closeArchive func() error, create func(name string, info fs.FileInfo) (io.Writer, error)) (report *BackupReport, err error) {
+	if javaServer.RCONAddress != "" || server.Running(javaServer.PID) {
+		if err = javaServer.SaveOff(ctx); err != nil {
+			return nil, errors.Join(err, javaServer.saveOn(ctx))
+		}
+		defer func() { err = errors.Join(err, javaServer.saveOn(ctx)) }()
+	}
+
+	roots := []string{"."}
+	if options.WorldsOnly {
+		levelName := "world"
+		if config, err := javaServer.Properties(); err == nil && config.LevelName != "" {
+			levelName = config.LevelName
+		}
+		roots = append([]string{levelName, levelName + "_nether", levelName + "_the_end"}, options.Include...)
+	}
+
+	report = &BackupReport{Files: []string{}, Changed: []string{}}
+	copied := map[string]fs.FileInfo{}
+	for _, root := range roots {
+		err = filepath.WalkDir(filepath.Join(javaServer.ServerStart.Cwd, root), func(fullPath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && root != "." {
+					return nil // Include or world dimension not exists
+				}
+				return err
+			} else if err = ctx.Err(); err != nil {
+				return err
+			} else if !entry.Type().IsRegular() || entry.Name() == "session.lock" {
+				return nil
+			}
+
+			name, err := filepath.Rel(javaServer.ServerStart.Cwd, fullPath)
+			if err != nil {
+				return err
+			}
+			name = filepath.ToSlash(name)
+			if _, ok := copied[name]; ok {
+				return nil
+			}
+
+			info, err := copyBackupFile(fullPath, name, create)
+			if err != nil {
+				return err
+			}
+			copied[name] = info
+			report.Files = append(report.Files, name)
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// Check files modified while copying
+	for _, name := range report.Files {
+		if current, err := os.Stat(filepath.Join(javaServer.ServerStart.Cwd, name)); err != nil || current.Size() != copied[name].Size() || !current.ModTime().Equal(copied[name].ModTime()) {
+			report.Changed = append(report.Changed, name)
+		}
+	}
+	return report, closeArchive()
+}
+
+// Run save-on even if ctx canceled
+func (javaServer *Server) saveOn(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), SaveOnTimeout)
+	defer cancel()
+	return javaServer.SaveOn(ctx)
+}
+
+func copyBackupFile(fullPath, name string, create func(name string, info fs.FileInfo) (io.Writer, error)) (fs.FileInfo, error) {
+	fileOpen, err := os.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer fileOpen.Close()
+
+	info, err := fileOpen.Stat()
+	if err != nil {
+		return nil, err
+	}
+	w, err := create(name, info)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.CopyN(w, fileOpen, info.Size())
+	return info, err
+}
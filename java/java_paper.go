@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/v2"
@@ -42,36 +43,43 @@ type paperBuilds struct {
 	} `json:"downloads"`
 }
 
-func paperWorkder(vers *Versions, ProjectTarget string, job <-chan paperBuilds, errPtr *error, wg *sync.WaitGroup) {
+func paperWorkder(vers *Versions, ProjectTarget string, job <-chan paperBuilds, errPtr *error, locker *sync.Mutex, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for latestBuild := range job {
-		if !slices.Contains(slices.Collect(maps.Keys(latestBuild.Downloads)), "application") {
-			continue
-		}
-
-		downloadUrl := fmt.Sprintf(paperProjectGetBuildsURL, ProjectTarget, latestBuild.Version, latestBuild.Build, latestBuild.Downloads["application"].Name)
+		downloadUrl := latestBuild.downloadURL(ProjectTarget)
 		jarFile, _, err := request.SaveTmp(downloadUrl, "", nil)
 		if err != nil {
 			*errPtr = err
 			continue
 		}
-		defer os.Remove(jarFile.Name())
-		defer jarFile.Close()
 		stat, _ := jarFile.Stat()
 		jvm, err := javaprebuild.JarMajor(jarFile, stat.Size())
+		jarFile.Close()
+		os.Remove(jarFile.Name())
 		if err != nil {
-			jarFile.Close()
-			os.Remove(jarFile.Name())
 			*errPtr = err
 			continue
 		}
-		jarFile.Close()
-		os.Remove(jarFile.Name())
-		*vers = append(*vers, GenericVersion{ServerVersion: latestBuild.Version, DownloadURL: downloadUrl, JVM: jvm})
+		vers.set(locker, GenericVersion{
+			ServerVersion: latestBuild.Version,
+			DownloadURL:   downloadUrl,
+			Hash:          cache.Hash{Algorithm: cache.SHA256, Sum: latestBuild.Downloads["application"].SHA256},
+			JVM:           jvm,
+			ReleaseDate:   latestBuild.BuildTime,
+			Proxy:         ProjectTarget == "velocity",
+		})
 	}
 }
 
-// Generic fetch to Paper Project
+// Jar url to build, empty if build not have server jar
+func (build paperBuilds) downloadURL(ProjectTarget string) string {
+	if !slices.Contains(slices.Collect(maps.Keys(build.Downloads)), "application") {
+		return ""
+	}
+	return fmt.Sprintf(paperProjectGetBuildsURL, ProjectTarget, build.Version, build.Build, build.Downloads["application"].Name)
+}
+
+// Generic fetch to Paper Project, jar is only downloaded to new builds
 func (vers *Versions) fetchPaperProject(ProjectTarget string) (err error) {
 	if !slices.Contains(PaperProjects, ProjectTarget) {
 		return fmt.Errorf("invalid paper project name: %s", ProjectTarget)
@@ -84,13 +92,13 @@ func (vers *Versions) fetchPaperProject(ProjectTarget string) (err error) {
 		return err
 	}
 
-	// Clean versions slice
-	*vers = (*vers)[:0]
+	known := vers.known()
 	var wg sync.WaitGroup
+	var locker sync.Mutex
 	jobs := make(chan paperBuilds)
 	for range runtime.NumCPU() * 2 {
 		wg.Add(1)
-		go paperWorkder(vers, ProjectTarget, jobs, &err, &wg)
+		go paperWorkder(vers, ProjectTarget, jobs, &err, &locker, &wg)
 	}
 
 	for _, version := range projectVersions.Versions {
@@ -99,12 +107,25 @@ func (vers *Versions) fetchPaperProject(ProjectTarget string) (err error) {
 		}
 		if _, err := request.DoJSON(fmt.Sprintf(paperProjectBuildsURL, ProjectTarget, version), &builds, nil); err != nil {
 			close(jobs)
+			wg.Wait()
 			return err
+		} else if len(builds.Builds) == 0 {
+			continue
 		}
 
 		latestBuild := builds.Builds[len(builds.Builds)-1]
 		latestBuild.Version = version
-		jobs <- latestBuild
+		downloadUrl := latestBuild.downloadURL(ProjectTarget)
+		if old, ok := known[version]; downloadUrl == "" {
+			continue
+		} else if ok && old.DownloadURL == downloadUrl && old.Hash.Sum == "" {
+			// Saved before hashes, jar not downloaded again
+			old.Hash = cache.Hash{Algorithm: cache.SHA256, Sum: latestBuild.Downloads["application"].SHA256}
+			old.Proxy = ProjectTarget == "velocity"
+			vers.set(&locker, old)
+		} else if !ok || old.DownloadURL != downloadUrl {
+			jobs <- latestBuild
+		}
 	}
 
 	close(jobs) // Done jobs
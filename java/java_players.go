@@ -0,0 +1,379 @@
+package java
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+const BanTimeLayout = "2006-01-02 15:04:05 -0700" // Date format in banned-players.json and banned-ips.json
+
+var (
+	ErrUUID error = errors.New("cannot resolve player uuid") // Player not found in resolver
+
+	DefaultUUIDResolver UUIDResolver = &MojangResolver{} // Resolver to online mode servers
+)
+
+// Get player UUID from username
+type UUIDResolver interface {
+	Resolve(ctx context.Context, name string) (PlayerID, error)
+}
+
+// Player UUID and username, used in whitelist.json
+type PlayerID struct {
+	UUID string `json:"uuid"` // Player UUID with dashes
+	Name string `json:"name"` // Player username
+}
+
+// Player in ops.json
+type Operator struct {
+	PlayerID
+	Level               int  `json:"level"`               // Permission level, 1 to 4
+	BypassesPlayerLimit bool `json:"bypassesPlayerLimit"` // Join if server is full
+}
+
+// Ban date, zero time is "forever" in expires
+type BanTime struct{ time.Time }
+
+func (date BanTime) MarshalText() ([]byte, error) {
+	if date.IsZero() {
+		return []byte("forever"), nil
+	}
+	return []byte(date.Format(BanTimeLayout)), nil
+}
+
+func (date *BanTime) UnmarshalText(text []byte) (err error) {
+	if date.Time = (time.Time{}); len(text) == 0 || string(text) == "forever" {
+		return nil
+	}
+	date.Time, err = time.Parse(BanTimeLayout, string(text))
+	return
+}
+
+// Replace JSON methods from [time.Time]
+func (date BanTime) MarshalJSON() ([]byte, error) {
+	text, _ := date.MarshalText()
+	return json.Marshal(string(text))
+}
+
+func (date *BanTime) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	return date.UnmarshalText([]byte(text))
+}
+
+// Common ban info
+type BanEntry struct {
+	Created BanTime `json:"created"` // Ban date
+	Source  string  `json:"source"`  // Who banned, example "Server" or operator name
+	Expires BanTime `json:"expires"` // Ban end, zero is forever
+	Reason  string  `json:"reason"`  // Ban reason
+}
+
+// Player in banned-players.json
+type BannedPlayer struct {
+	PlayerID
+	BanEntry
+}
+
+// Address in banned-ips.json
+type BannedIP struct {
+	IP string `json:"ip"` // Banned address
+	BanEntry
+}
+
+type (
+	Operators     []Operator     // ops.json
+	Whitelist     []PlayerID     // whitelist.json
+	BannedPlayers []BannedPlayer // banned-players.json
+	BannedIPs     []BannedIP     // banned-ips.json
+)
+
+// Name-based UUIDv3 from "OfflinePlayer:<name>", used by servers with online-mode=false
+func OfflineUUID(name string) string {
+	sum := md5.Sum([]byte("OfflinePlayer:" + name))
+	sum[6] = (sum[6] & 0x0f) | 0x30 // Version 3
+	sum[8] = (sum[8] & 0x3f) | 0x80 // Variant RFC 4122
+	return formatUUID(sum[:])
+}
+
+// Resolve UUID with Mojang API or compatible
+type MojangResolver struct {
+	URL    string       // Profile endpoint, username is appended, default is "https://api.mojang.com/users/profiles/minecraft/"
+	Client *http.Client // HTTP client, default is [http.DefaultClient]
+}
+
+func (resolver MojangResolver) Resolve(ctx context.Context, name string) (PlayerID, error) {
+	if resolver.URL == "" {
+		resolver.URL = "https://api.mojang.com/users/profiles/minecraft/"
+	}
+	if resolver.Client == nil {
+		resolver.Client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resolver.URL+url.PathEscape(name), nil)
+	if err != nil {
+		return PlayerID{}, err
+	}
+	res, err := resolver.Client.Do(req)
+	if err != nil {
+		return PlayerID{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusNoContent {
+		return PlayerID{}, fmt.Errorf("%w: %s not exists", ErrUUID, name)
+	} else if res.StatusCode != http.StatusOK {
+		return PlayerID{}, fmt.Errorf("%w: %s", ErrUUID, res.Status)
+	}
+
+	var profile struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&profile); err != nil {
+		return PlayerID{}, err
+	}
+	id, err := hex.DecodeString(strings.ReplaceAll(profile.ID, "-", ""))
+	if err != nil || len(id) != 16 {
+		return PlayerID{}, fmt.Errorf("%w: invalid id %q", ErrUUID, profile.ID)
+	}
+	return PlayerID{UUID: formatUUID(id), Name: profile.Name}, nil
+}
+
+// Resolve player UUID, offline UUID if online-mode is false in server.properties else UUIDResolver
+func (javaServer *Server) ResolvePlayer(ctx context.Context, name string) (PlayerID, error) {
+	if config, err := javaServer.Properties(); err == nil && !config.OnlineMode {
+		return PlayerID{UUID: OfflineUUID(name), Name: name}, nil
+	}
+	resolver := javaServer.UUIDResolver
+	if resolver == nil {
+		resolver = DefaultUUIDResolver
+	}
+	return resolver.Resolve(ctx, name)
+}
+
+// Read ops.json from server folder
+func (javaServer *Server) Ops() (Operators, error) {
+	return loadList[Operators](javaServer.listFile("ops.json"))
+}
+
+// Add or update operator, if server running send "op" command
+//
+// Server set op-permission-level to players added with command, level is kept only with server stopped
+func (javaServer *Server) Op(ctx context.Context, name string, level int, bypassesPlayerLimit bool) error {
+	ops, err := javaServer.Ops()
+	if err != nil {
+		return err
+	}
+
+	player, err := findPlayer(ctx, javaServer, name, ops, func(op Operator) PlayerID { return op.PlayerID })
+	if err != nil {
+		return err
+	}
+	ops = slices.DeleteFunc(ops, func(op Operator) bool { return op.UUID == player.UUID })
+	ops = append(ops, Operator{PlayerID: player, Level: level, BypassesPlayerLimit: bypassesPlayerLimit})
+	if err = saveList(javaServer.listFile("ops.json"), ops); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "op "+player.Name)
+}
+
+// Remove operator, if server running send "deop" command
+func (javaServer *Server) Deop(ctx context.Context, name string) error {
+	ops, err := javaServer.Ops()
+	if err != nil {
+		return err
+	}
+	size := len(ops)
+	if ops = slices.DeleteFunc(ops, func(op Operator) bool { return strings.EqualFold(op.Name, name) }); size == len(ops) {
+		return logs.ErrPlayerNotExist
+	} else if err = saveList(javaServer.listFile("ops.json"), ops); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "deop "+name)
+}
+
+// Read whitelist.json from server folder
+func (javaServer *Server) Whitelist() (Whitelist, error) {
+	return loadList[Whitelist](javaServer.listFile("whitelist.json"))
+}
+
+// Add player to whitelist, if server running send "whitelist add" command
+func (javaServer *Server) WhitelistAdd(ctx context.Context, name string) error {
+	list, err := javaServer.Whitelist()
+	if err != nil {
+		return err
+	}
+
+	player, err := findPlayer(ctx, javaServer, name, list, func(player PlayerID) PlayerID { return player })
+	if err != nil {
+		return err
+	}
+	list = append(slices.DeleteFunc(list, func(current PlayerID) bool { return current.UUID == player.UUID }), player)
+	if err = saveList(javaServer.listFile("whitelist.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "whitelist add "+player.Name)
+}
+
+// Remove player from whitelist, if server running send "whitelist remove" command
+func (javaServer *Server) WhitelistRemove(ctx context.Context, name string) error {
+	list, err := javaServer.Whitelist()
+	if err != nil {
+		return err
+	}
+	size := len(list)
+	if list = slices.DeleteFunc(list, func(player PlayerID) bool { return strings.EqualFold(player.Name, name) }); size == len(list) {
+		return logs.ErrPlayerNotExist
+	} else if err = saveList(javaServer.listFile("whitelist.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "whitelist remove "+name)
+}
+
+// Read banned-players.json from server folder
+func (javaServer *Server) BannedPlayers() (BannedPlayers, error) {
+	return loadList[BannedPlayers](javaServer.listFile("banned-players.json"))
+}
+
+// Ban player, zero expires is forever. If server running send "ban" command
+//
+// Server not accept expires in command, expires is kept only with server stopped
+func (javaServer *Server) Ban(ctx context.Context, name, reason string, expires time.Time) error {
+	list, err := javaServer.BannedPlayers()
+	if err != nil {
+		return err
+	}
+
+	player, err := findPlayer(ctx, javaServer, name, list, func(ban BannedPlayer) PlayerID { return ban.PlayerID })
+	if err != nil {
+		return err
+	}
+	list = slices.DeleteFunc(list, func(ban BannedPlayer) bool { return ban.UUID == player.UUID })
+	list = append(list, BannedPlayer{PlayerID: player, BanEntry: newBanEntry(reason, expires)})
+	if err = saveList(javaServer.listFile("banned-players.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, strings.TrimSpace("ban "+player.Name+" "+reason))
+}
+
+// Remove player ban, if server running send "pardon" command
+func (javaServer *Server) Pardon(ctx context.Context, name string) error {
+	list, err := javaServer.BannedPlayers()
+	if err != nil {
+		return err
+	}
+	size := len(list)
+	if list = slices.DeleteFunc(list, func(ban BannedPlayer) bool { return strings.EqualFold(ban.Name, name) }); size == len(list) {
+		return logs.ErrPlayerNotExist
+	} else if err = saveList(javaServer.listFile("banned-players.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "pardon "+name)
+}
+
+// Read banned-ips.json from server folder
+func (javaServer *Server) BannedIPs() (BannedIPs, error) {
+	return loadList[BannedIPs](javaServer.listFile("banned-ips.json"))
+}
+
+// Ban address, zero expires is forever. If server running send "ban-ip" command
+func (javaServer *Server) BanIP(ctx context.Context, ip, reason string, expires time.Time) error {
+	list, err := javaServer.BannedIPs()
+	if err != nil {
+		return err
+	}
+	list = slices.DeleteFunc(list, func(ban BannedIP) bool { return ban.IP == ip })
+	list = append(list, BannedIP{IP: ip, BanEntry: newBanEntry(reason, expires)})
+	if err = saveList(javaServer.listFile("banned-ips.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, strings.TrimSpace("ban-ip "+ip+" "+reason))
+}
+
+// Remove address ban, if server running send "pardon-ip" command
+func (javaServer *Server) PardonIP(ctx context.Context, ip string) error {
+	list, err := javaServer.BannedIPs()
+	if err != nil {
+		return err
+	}
+	size := len(list)
+	if list = slices.DeleteFunc(list, func(ban BannedIP) bool { return ban.IP == ip }); size == len(list) {
+		return logs.ErrPlayerNotExist
+	} else if err = saveList(javaServer.listFile("banned-ips.json"), list); err != nil {
+		return err
+	}
+	return javaServer.pushCommand(ctx, "pardon-ip "+ip)
+}
+
+// Get player from list if exists, else resolve UUID
+func findPlayer[T any](ctx context.Context, javaServer *Server, name string, list []T, id func(T) PlayerID) (PlayerID, error) {
+	for _, entry := range list {
+		if player := id(entry); strings.EqualFold(player.Name, name) && player.UUID != "" {
+			return player, nil
+		}
+	}
+	return javaServer.ResolvePlayer(ctx, name)
+}
+
+// Send command if server running or RCONAddress is set, so server memory is same of files
+func (javaServer *Server) pushCommand(ctx context.Context, command string) error {
+	if javaServer.RCONAddress == "" && !server.Running(javaServer.PID) {
+		return nil
+	}
+	_, err := javaServer.RunCommand(ctx, command)
+	return err
+}
+
+func (javaServer *Server) listFile(name string) string {
+	return filepath.Join(javaServer.ServerStart.Cwd, name)
+}
+
+func newBanEntry(reason string, expires time.Time) BanEntry {
+	if reason == "" {
+		reason = "Banned by an operator."
+	}
+	return BanEntry{Created: BanTime{time.Now()}, Source: "Server", Expires: BanTime{expires}, Reason: reason}
+}
+
+func formatUUID(id []byte) string {
+	text := hex.EncodeToString(id)
+	return text[:8] + "-" + text[8:12] + "-" + text[12:16] + "-" + text[16:20] + "-" + text[20:]
+}
+
+func loadList[T any](file string) (T, error) {
+	var list T
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return list, nil
+		}
+		return list, err
+	} else if len(strings.TrimSpace(string(data))) == 0 {
+		return list, nil
+	}
+	return list, json.Unmarshal(data, &list)
+}
+
+func saveList(file string, list any) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
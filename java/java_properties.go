@@ -0,0 +1,367 @@
+package java
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/utils/properties"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
+)
+
+var ErrInvalidProperty error = errors.New("invalid server.properties value") // Value out of range or not valid option
+
+// First version with key in server.properties, keys not listed exists in all versions supported
+var PropertiesSince = map[string]string{
+	"max-tick-time":                     "1.8",
+	"network-compression-threshold":     "1.8",
+	"prevent-proxy-connections":         "1.11",
+	"function-permission-level":         "1.14.4",
+	"enable-jmx-monitoring":             "1.16",
+	"enable-status":                     "1.16",
+	"entity-broadcast-range-percentage": "1.16",
+	"sync-chunk-writes":                 "1.16",
+	"rate-limit":                        "1.16.2",
+	"text-filtering-config":             "1.16.4",
+	"require-resource-pack":             "1.17",
+	"resource-pack-prompt":              "1.17",
+	"hide-online-players":               "1.18",
+	"simulation-distance":               "1.18",
+	"enforce-secure-profile":            "1.19",
+	"max-chained-neighbor-updates":      "1.19",
+	"initial-disabled-packs":            "1.19.3",
+	"initial-enabled-packs":             "1.19.3",
+	"log-ips":                           "1.20.2",
+	"resource-pack-id":                  "1.20.3",
+	"accepts-transfers":                 "1.20.5",
+	"region-file-compression":           "1.20.5",
+	"bug-report-link":                   "1.21",
+	"pause-when-empty-seconds":          "1.21.2",
+	"text-filtering-version":            "1.21.2",
+}
+
+// Java server.properties to vanilla server
+type ServerProperties struct {
+	AcceptsTransfers               bool   `properties:"accepts-transfers"`                 // Accept transfer packets from other servers
+	AllowFlight                    bool   `properties:"allow-flight"`                      // Not kick players flying in survival
+	AllowNether                    bool   `properties:"allow-nether"`                      // Players can travel to nether
+	BroadcastConsoleToOps          bool   `properties:"broadcast-console-to-ops"`          // Send console commands output to ops
+	BroadcastRconToOps             bool   `properties:"broadcast-rcon-to-ops"`             // Send rcon commands output to ops
+	BugReportLink                  string `properties:"bug-report-link"`                   // Link showed in disconnect screen
+	Difficulty                     string `properties:"difficulty"`                        // "peaceful", "easy", "normal" or "hard"
+	EnableCommandBlock             bool   `properties:"enable-command-block"`              // Enable command blocks
+	EnableJmxMonitoring            bool   `properties:"enable-jmx-monitoring"`             // Expose tick times in JMX
+	EnableStatus                   bool   `properties:"enable-status"`                     // Show server as online in server list
+	EnforceSecureProfile           bool   `properties:"enforce-secure-profile"`            // Require players with Mojang signed public key
+	EnforceWhitelist               bool   `properties:"enforce-whitelist"`                 // Kick players not in whitelist on reload
+	EntityBroadcastRangePercentage int    `properties:"entity-broadcast-range-percentage"` // Entity send distance, 10 to 1000
+	ForceGamemode                  bool   `properties:"force-gamemode"`                    // Force default gamemode on join
+	FunctionPermissionLevel        int    `properties:"function-permission-level"`         // Functions permission level, 1 to 4
+	Gamemode                       string `properties:"gamemode"`                          // "survival", "creative", "adventure" or "spectator"
+	GenerateStructures             bool   `properties:"generate-structures"`               // Generate villages and others structures
+	GeneratorSettings              string `properties:"generator-settings"`                // JSON to custom world generator
+	Hardcore                       bool   `properties:"hardcore"`                          // Hardcore mode
+	HideOnlinePlayers              bool   `properties:"hide-online-players"`               // Hide player list from status
+	InitialDisabledPacks           string `properties:"initial-disabled-packs"`            // Datapacks not enabled on world creation
+	InitialEnabledPacks            string `properties:"initial-enabled-packs"`             // Datapacks enabled on world creation
+	LevelName                      string `properties:"level-name"`                        // World folder name
+	LevelSeed                      string `properties:"level-seed"`                        // Seed to new world
+	LevelType                      string `properties:"level-type"`                        // World preset, example "minecraft:normal"
+	LogIPs                         bool   `properties:"log-ips"`                           // Log players ip on join
+	MaxChainedNeighborUpdates      int    `properties:"max-chained-neighbor-updates"`      // Limit consecutive neighbor updates, negative to disable
+	MaxPlayers                     int    `properties:"max-players"`                       // Max players online
+	MaxTickTime                    int    `properties:"max-tick-time"`                     // Max milliseconds to single tick before watchdog stop server, -1 to disable
+	MaxWorldSize                   int    `properties:"max-world-size"`                    // World border radius, 1 to 29999984
+	Motd                           string `properties:"motd"`                              // Message showed in server list
+	NetworkCompressionThreshold    int    `properties:"network-compression-threshold"`     // Min packet size to compress, -1 to disable
+	OnlineMode                     bool   `properties:"online-mode"`                       // Check players with Mojang
+	OpPermissionLevel              int    `properties:"op-permission-level"`               // Default ops permission level, 0 to 4
+	PauseWhenEmptySeconds          int    `properties:"pause-when-empty-seconds"`          // Seconds without players to pause server, 0 to disable
+	PlayerIdleTimeout              int    `properties:"player-idle-timeout"`               // Minutes to kick idle players, 0 to disable
+	PreventProxyConnections        bool   `properties:"prevent-proxy-connections"`         // Kick players with different ip of Mojang session
+	PVP                            bool   `properties:"pvp"`                               // Players can damage others players
+	Query                          struct {
+		Port int `properties:"port"` // Query UDP port
+	} `properties:"query"`
+	EnableQuery bool `properties:"enable-query"` // Enable GameSpy4 query
+	RateLimit   int  `properties:"rate-limit"`   // Max packets per second before kick, 0 to disable
+	RCON        struct {
+		Password string `properties:"password"` // RCON password
+		Port     int    `properties:"port"`     // RCON TCP port
+	} `properties:"rcon"`
+	EnableRCON            bool              `properties:"enable-rcon"`             // Enable RCON
+	RegionFileCompression string            `properties:"region-file-compression"` // "deflate", "lz4" or "none"
+	RequireResourcePack   bool              `properties:"require-resource-pack"`   // Kick players that decline resource pack
+	ResourcePack          string            `properties:"resource-pack"`           // Resource pack URL
+	ResourcePackID        string            `properties:"resource-pack-id"`        // Resource pack UUID
+	ResourcePackPrompt    string            `properties:"resource-pack-prompt"`    // JSON text showed in resource pack prompt
+	ResourcePackSha1      string            `properties:"resource-pack-sha1"`      // Resource pack SHA-1
+	ServerIP              string            `properties:"server-ip"`               // Listen address, blank to all
+	ServerPort            int               `properties:"server-port"`             // Server TCP port
+	SimulationDistance    int               `properties:"simulation-distance"`     // Chunks from players to tick, 3 to 32
+	SpawnMonsters         bool              `properties:"spawn-monsters"`          // Spawn monsters
+	SpawnProtection       int               `properties:"spawn-protection"`        // Spawn radius protected from non ops, 0 to disable
+	SyncChunkWrites       bool              `properties:"sync-chunk-writes"`       // Write chunks synchronously
+	TextFilteringConfig   string            `properties:"text-filtering-config"`   // Chat filter config
+	TextFilteringVersion  int               `properties:"text-filtering-version"`  // Chat filter version
+	UseNativeTransport    bool              `properties:"use-native-transport"`    // Use epoll on Linux
+	ViewDistance          int               `properties:"view-distance"`           // Chunks send to players, 3 to 32
+	WhiteList             bool              `properties:"white-list"`              // Only players in whitelist.json can join
+	ExtraKeys             map[string]string `properties:"-"`                       // Keys not mapped in struct (plugins, old or new versions), kept on save
+}
+
+// Default values from vanilla server
+func DefaultServerProperties() *ServerProperties {
+	config := &ServerProperties{
+		AllowNether:                    true,
+		BroadcastConsoleToOps:          true,
+		BroadcastRconToOps:             true,
+		Difficulty:                     "easy",
+		EnableStatus:                   true,
+		EnforceSecureProfile:           true,
+		EntityBroadcastRangePercentage: 100,
+		FunctionPermissionLevel:        2,
+		Gamemode:                       "survival",
+		GenerateStructures:             true,
+		GeneratorSettings:              "{}",
+		InitialEnabledPacks:            "vanilla",
+		LevelName:                      "world",
+		LevelType:                      "minecraft:normal",
+		LogIPs:                         true,
+		MaxChainedNeighborUpdates:      1000000,
+		MaxPlayers:                     20,
+		MaxTickTime:                    60000,
+		MaxWorldSize:                   29999984,
+		Motd:                           "A Minecraft Server",
+		NetworkCompressionThreshold:    256,
+		OnlineMode:                     true,
+		OpPermissionLevel:              4,
+		PauseWhenEmptySeconds:          60,
+		PVP:                            true,
+		RegionFileCompression:          "deflate",
+		ServerPort:                     25565,
+		SimulationDistance:             10,
+		SpawnMonsters:                  true,
+		SpawnProtection:                16,
+		SyncChunkWrites:                true,
+		UseNativeTransport:             true,
+		ViewDistance:                   10,
+		ExtraKeys:                      map[string]string{},
+	}
+	config.Query.Port, config.RCON.Port = 25565, 25575
+	return config
+}
+
+// Default values from vanilla server to version, old versions use old level-type,
+// generator-settings and numeric difficulty and gamemode
+func DefaultVersionProperties(version Version) *ServerProperties {
+	config := DefaultServerProperties()
+	if version == nil {
+		return config
+	}
+	target := semver.New(version.Version())
+	if target == nil {
+		return config
+	}
+	if target.LessThan(semver.New("1.19")) {
+		config.LevelType = "default"
+	}
+	if target.LessThan(semver.New("1.18")) {
+		config.GeneratorSettings = ""
+	}
+	if target.LessThan(semver.New("1.14")) {
+		config.Difficulty, config.Gamemode = "1", "0"
+	}
+	return config
+}
+
+// Read server.properties, keys not in file keep default value
+func LoadServerProperties(file string) (*ServerProperties, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	config := DefaultServerProperties()
+	return config, config.UnmarshalProperties(data)
+}
+
+// Decode properties and keep keys not mapped in ExtraKeys
+func (config *ServerProperties) UnmarshalProperties(data []byte) error {
+	reader := properties.NewParse(bytes.NewReader(data))
+	if err := reader.Decode(config); err != nil {
+		return err
+	}
+
+	values, err := reader.Values()
+	if err != nil {
+		return err
+	}
+	if config.ExtraKeys == nil {
+		config.ExtraKeys = map[string]string{}
+	}
+	known := propertiesKeys(reflect.TypeFor[ServerProperties]())
+	for key, value := range properties.Flat(values) {
+		if !slices.Contains(known, key) {
+			config.ExtraKeys[key] = value
+		}
+	}
+	return nil
+}
+
+// Encode properties with ExtraKeys in end of file, if version is not nil
+// keys not supported by version are not writed
+func (config ServerProperties) MarshalProperties(version Version) ([]byte, error) {
+	data, err := properties.Marshal(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	for line := range strings.Lines(string(data)) {
+		key, _, _ := strings.Cut(line, " = ")
+		if version == nil || keySupported(key, version) {
+			lines = append(lines, line)
+		}
+	}
+
+	keys := make([]string, 0, len(config.ExtraKeys))
+	for key := range config.ExtraKeys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s = %s\n", key, config.ExtraKeys[key]))
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// Validate values and write to file, keys not supported by version are skipped and
+// skipped keys changed from version default are returned as warning, see [ServerProperties.Unsupported]
+func (config ServerProperties) Save(file string, version Version) (skipped []string, err error) {
+	if err = config.Validate(); err != nil {
+		return nil, err
+	}
+	data, err := config.MarshalProperties(version)
+	if err != nil {
+		return nil, err
+	}
+	return config.Unsupported(version), os.WriteFile(file, data, 0644)
+}
+
+// Return keys changed from default value but not supported by server version,
+// example simulation-distance before 1.18
+func (config ServerProperties) Unsupported(version Version) []string {
+	if version == nil {
+		return nil
+	}
+
+	keys := []string{}
+	current, defaults := reflect.ValueOf(config), reflect.ValueOf(*DefaultVersionProperties(version))
+	for index := range current.NumField() {
+		key := current.Type().Field(index).Tag.Get("properties")
+		if key == "" || key == "-" || keySupported(key, version) {
+			continue
+		} else if !reflect.DeepEqual(current.Field(index).Interface(), defaults.Field(index).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Check enums and ranges, return all invalid values
+func (config ServerProperties) Validate() error {
+	errs := []error{}
+	invalid := func(key string, value any, expected string) {
+		errs = append(errs, fmt.Errorf("%w: %s = %v, expected %s", ErrInvalidProperty, key, value, expected))
+	}
+	inRange := func(key string, value, minValue, maxValue int) {
+		if value < minValue || value > maxValue {
+			invalid(key, value, fmt.Sprintf("%d to %d", minValue, maxValue))
+		}
+	}
+
+	// Before 1.14 difficulty and gamemode is number
+	if !slices.Contains([]string{"peaceful", "easy", "normal", "hard", "0", "1", "2", "3"}, config.Difficulty) {
+		invalid("difficulty", config.Difficulty, "peaceful, easy, normal, hard or 0 to 3")
+	}
+	if !slices.Contains([]string{"survival", "creative", "adventure", "spectator", "0", "1", "2", "3"}, config.Gamemode) {
+		invalid("gamemode", config.Gamemode, "survival, creative, adventure, spectator or 0 to 3")
+	}
+	if !slices.Contains([]string{"deflate", "lz4", "none"}, config.RegionFileCompression) {
+		invalid("region-file-compression", config.RegionFileCompression, "deflate, lz4 or none")
+	}
+
+	inRange("entity-broadcast-range-percentage", config.EntityBroadcastRangePercentage, 10, 1000)
+	inRange("function-permission-level", config.FunctionPermissionLevel, 1, 4)
+	inRange("op-permission-level", config.OpPermissionLevel, 0, 4)
+	inRange("max-world-size", config.MaxWorldSize, 1, 29999984)
+	inRange("server-port", config.ServerPort, 1, 65535)
+	inRange("query.port", config.Query.Port, 1, 65535)
+	inRange("rcon.port", config.RCON.Port, 1, 65535)
+	inRange("view-distance", config.ViewDistance, 3, 32)
+	inRange("simulation-distance", config.SimulationDistance, 3, 32)
+	if config.MaxPlayers < 0 {
+		invalid("max-players", config.MaxPlayers, "0 or more")
+	}
+	if config.SpawnProtection < 0 {
+		invalid("spawn-protection", config.SpawnProtection, "0 or more")
+	}
+	if config.PlayerIdleTimeout < 0 {
+		invalid("player-idle-timeout", config.PlayerIdleTimeout, "0 or more")
+	}
+	if config.EnableRCON && config.RCON.Password == "" {
+		invalid("rcon.password", `""`, "password when enable-rcon is true")
+	}
+	if config.LevelName == "" {
+		invalid("level-name", `""`, "folder name")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Read server.properties from server folder
+func (javaServer *Server) Properties() (*ServerProperties, error) {
+	return LoadServerProperties(filepath.Join(javaServer.ServerStart.Cwd, "server.properties"))
+}
+
+// Validate and write server.properties to server folder with keys supported by server version,
+// return keys changed but not supported by server version as warning
+func (javaServer *Server) SetProperties(config *ServerProperties) ([]string, error) {
+	return config.Save(filepath.Join(javaServer.ServerStart.Cwd, "server.properties"), javaServer.Version)
+}
+
+// Check if key exists in version, versions not semver like snapshots support all keys
+func keySupported(key string, version Version) bool {
+	since, ok := PropertiesSince[key]
+	if !ok {
+		return true
+	}
+	target := semver.New(version.Version())
+	if target == nil {
+		return true
+	}
+	return !target.LessThan(semver.New(since))
+}
+
+// Keys from struct tags, nested structs return "struct.key"
+func propertiesKeys(structType reflect.Type) []string {
+	keys := []string{}
+	for index := range structType.NumField() {
+		field := structType.Field(index)
+		if key := strings.Split(field.Tag.Get("properties"), ",")[0]; key == "" || key == "-" {
+			continue
+		} else if field.Type.Kind() == reflect.Struct {
+			for _, subKey := range propertiesKeys(field.Type) {
+				keys = append(keys, key+"."+subKey)
+			}
+		} else {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
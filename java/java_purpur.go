@@ -7,13 +7,14 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/v2"
 )
 
-func purpurWorkder(vers *Versions, job <-chan string, wg *sync.WaitGroup) {
+func purpurWorkder(vers *Versions, known map[string]GenericVersion, job <-chan string, locker *sync.Mutex, wg *sync.WaitGroup) {
 	defer wg.Done()
 	type buildTargetInfo struct {
 		MCStarget string `json:"version"`
@@ -48,32 +49,31 @@ func purpurWorkder(vers *Versions, job <-chan string, wg *sync.WaitGroup) {
 		}
 
 		downloadUrl := fmt.Sprintf("https://api.purpurmc.org/v2/purpur/%s/%s/download", Version, resBuild.Build)
+		if known[resBuild.MCStarget].DownloadURL == downloadUrl {
+			continue // Build already fetched
+		}
 		jarFile, _, err := request.SaveTmp(downloadUrl, "", nil)
 		if err != nil {
 			continue
 		}
-		defer os.Remove(jarFile.Name())
-		defer jarFile.Close()
 		stat, _ := jarFile.Stat()
-
 		jvm, err := javaprebuild.JarMajor(jarFile, stat.Size())
+		jarFile.Close()
+		os.Remove(jarFile.Name())
 		if err != nil {
-			jarFile.Close()
-			os.Remove(jarFile.Name())
 			continue
 		}
-		jarFile.Close()
-		os.Remove(jarFile.Name())
 
-		*vers = append(*vers, GenericVersion{
+		vers.set(locker, GenericVersion{
 			ServerVersion: resBuild.MCStarget,
 			DownloadURL:   downloadUrl,
 			JVM:           jvm,
+			ReleaseDate:   time.UnixMilli(resBuild.Time).UTC(),
 		})
 	}
 }
 
-// Fetch versions from purpur API
+// Fetch versions from purpur API, jar is only downloaded to new builds
 func (vers *Versions) FetchPurpurVersions() error {
 	type projectVersions struct {
 		Versions []string `json:"versions"`
@@ -83,12 +83,13 @@ func (vers *Versions) FetchPurpurVersions() error {
 		return err
 	}
 
-	*vers = (*vers)[:0]
+	known := vers.known()
 	jobs := make(chan string)
 	var wg sync.WaitGroup
+	var locker sync.Mutex
 	for range runtime.NumCPU() * 4 {
 		wg.Add(1)
-		go purpurWorkder(vers, jobs, &wg)
+		go purpurWorkder(vers, known, jobs, &locker, &wg)
 	}
 
 	for _, version := range versions.Versions {
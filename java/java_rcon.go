@@ -0,0 +1,132 @@
+package java
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/java/rcon"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/properties"
+)
+
+var ErrNoRCON error = errors.New("rcon not enabled in server.properties") // enable-rcon is false
+
+// RCON keys from server.properties
+type rconProperties struct {
+	Enable bool `properties:"enable-rcon"`
+	RCON   struct {
+		Port     int    `properties:"port"`
+		Password string `properties:"password"`
+	} `properties:"rcon"`
+}
+
+// Connect to server RCON with password from server.properties.
+//
+// Address is RCONAddress if set, else rcon.port from server.properties,
+// port listened in logs/latest.log or [rcon.DefaultPort] in localhost.
+func (javaServer *Server) RCON(ctx context.Context) (*rcon.Client, error) {
+	data, err := os.ReadFile(filepath.Join(javaServer.ServerStart.Cwd, "server.properties"))
+	if err != nil {
+		return nil, err
+	}
+
+	var config rconProperties
+	if err := properties.Unmarshal(data, &config); err != nil {
+		return nil, err
+	} else if !config.Enable {
+		return nil, ErrNoRCON
+	}
+
+	address := javaServer.RCONAddress
+	if address == "" {
+		port := config.RCON.Port
+		if port == 0 {
+			port = javaServer.rconLogPort()
+		}
+		address = net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	}
+	return rcon.Dial(ctx, address, config.RCON.Password)
+}
+
+// Find RCON port in last server log, return [rcon.DefaultPort] if not found
+func (javaServer *Server) rconLogPort() int {
+	logFile, err := os.Open(filepath.Join(javaServer.ServerStart.Cwd, "logs/latest.log"))
+	if err != nil {
+		return rcon.DefaultPort
+	}
+	defer logFile.Close()
+
+	var log javalog.JavaParse
+	if log.Parse(logFile) == nil {
+		for _, port := range log.ServerPlaform.Ports {
+			if port.From == "RCON" {
+				return int(port.AddrPort.Port())
+			}
+		}
+	}
+	return rcon.DefaultPort
+}
+
+// Cached connection or new connection with [Server.RCON], return true if connection is cached
+func (javaServer *Server) rconConn(ctx context.Context) (*rcon.Client, bool, error) {
+	javaServer.rconLocker.Lock()
+	client := javaServer.rconClient
+	javaServer.rconLocker.Unlock()
+	if client != nil {
+		return client, true, nil
+	}
+
+	client, err := javaServer.RCON(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	javaServer.rconLocker.Lock()
+	defer javaServer.rconLocker.Unlock()
+	if javaServer.rconClient != nil { // Connected by other command
+		client.Close()
+		return javaServer.rconClient, true, nil
+	}
+	javaServer.rconClient = client
+	return client, false, nil
+}
+
+// Close connection and remove from cache
+func (javaServer *Server) dropRCON(client *rcon.Client) {
+	javaServer.rconLocker.Lock()
+	if javaServer.rconClient == client {
+		javaServer.rconClient = nil
+	}
+	javaServer.rconLocker.Unlock()
+	client.Close()
+}
+
+// Run command over RCON and split response in lines, connection is kept to next commands.
+// sent is false if server not received command (dial, auth or write fail), only then
+// command is sent again in new connection or console, so commands never run twice
+func (javaServer *Server) rconCommand(ctx context.Context, command string) (lines []string, sent bool, err error) {
+	client, cached, err := javaServer.rconConn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	response, err := client.Command(ctx, command)
+	if errors.Is(err, rcon.ErrSend) && cached && ctx.Err() == nil {
+		javaServer.dropRCON(client) // Closed connection, example after server restart
+		if client, _, err = javaServer.rconConn(ctx); err != nil {
+			return nil, false, err
+		}
+		response, err = client.Command(ctx, command)
+	}
+	if err != nil {
+		javaServer.dropRCON(client)
+		return nil, !errors.Is(err, rcon.ErrSend) && !errors.Is(err, rcon.ErrCommand), err
+	} else if response = strings.TrimSpace(response); response == "" {
+		return []string{}, true, nil
+	}
+	return strings.Split(response, "\n"), true, nil
+}
@@ -4,27 +4,43 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"strconv"
-	"syscall"
+	"strings"
+	"sync"
 
 	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/java/rcon"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 )
 
+var _ server.Server = &Server{}
+
 // Prepare folder to server
 func NewServer(version Version, versionFolder, javaFolder, cwd string) (*Server, error) {
 	if version == nil {
 		return nil, ErrNoVersion
 	}
 
-	// Check if server exists
+	// Check if server exists and installed jar is not corrupted
 	serverFile := filepath.Join(versionFolder, version.Version(), "server.jar")
+	if generic, ok := version.(GenericVersion); ok && file_checker.IsFile(serverFile) {
+		if err := generic.Verify(filepath.Dir(serverFile)); errors.Is(err, cache.ErrHash) {
+			os.Remove(serverFile)
+		} else if err != nil {
+			return nil, err
+		}
+	}
 	if !file_checker.IsFile(serverFile) {
-		if err := version.Install(filepath.Dir(serverFile)); err != nil {
+		if err := version.Install(context.Background(), filepath.Dir(serverFile), nil); err != nil {
 			return nil, err
 		}
 	}
@@ -40,8 +56,15 @@ func NewServer(version Version, versionFolder, javaFolder, cwd string) (*Server,
 		}
 	}
 
+	// server.properties with vanilla defaults and keys supported by version, proxies use other config
+	if propertiesFile := filepath.Join(cwd, "server.properties"); !IsProxy(version) && !file_checker.IsFile(propertiesFile) {
+		if _, err := DefaultVersionProperties(version).Save(propertiesFile, version); err != nil {
+			return nil, err
+		}
+	}
+
 	// Java binary path
-	javaPath, err := version.JavaVersion().Install(filepath.Join(javaFolder, strconv.Itoa(int(version.JavaVersion()))))
+	javaPath, err := version.JavaVersion().Install(context.Background(), filepath.Join(javaFolder, strconv.Itoa(int(version.JavaVersion()))), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -64,53 +87,111 @@ func NewServer(version Version, versionFolder, javaFolder, cwd string) (*Server,
 }
 
 type Server struct {
-	PID         exec.Proc     // Struct to start server
-	ServerStart exec.ProcExec // Process start
-	Version     Version       // Server info
+	PID         exec.Proc          // Struct to start server
+	ServerStart exec.ProcExec      // Process start
+	StopConfig  server.StopOptions // Stop stages config
+	Version     Version            // Server info
+	RCONAddress string             // RCON address to remote or container servers, if blank use rcon.port from server.properties
 
-	stopCalled int8 // Server call stop command
+	UUIDResolver UUIDResolver // Resolve players UUID in online mode, if nil use [DefaultUUIDResolver]
+
+	restored   *server.RestorePoint // Files replaced by Restore
+	rconLocker sync.Mutex           // Guard rconClient
+	rconClient *rcon.Client         // Cached RCON connection
 }
 
-// Make server backup with [*archive/tar.Writer]
-func (javaServer Server) Tar(w io.Writer) error {
+// Make server backup with [*archive/tar.Writer], to running server use [*Server.HotTar]
+func (javaServer *Server) Tar(w io.Writer) error {
 	tarball := tar.NewWriter(w)
 	defer tarball.Close()
 	return tarball.AddFS(os.DirFS(javaServer.ServerStart.Cwd))
 }
 
-// Make server backup with [*archive/zip.Writer]
-func (javaServer Server) Zip(w io.Writer) error {
+// Make server backup with [*archive/zip.Writer], to running server use [*Server.HotZip]
+func (javaServer *Server) Zip(w io.Writer) error {
 	wr := zip.NewWriter(w)
 	defer wr.Close()
 	return wr.AddFS(os.DirFS(javaServer.ServerStart.Cwd))
 }
 
 // Start server
-func (javaServer *Server) Start() error {
+func (javaServer *Server) Start(ctx context.Context) (err error) {
 	// if server not configured correctly return error
 	if javaServer == nil || javaServer.PID == nil {
 		return errors.New("cannot start server, server proc not defined")
 	}
 
+	// Keep or rollback restored files
+	defer func() { err = javaServer.restored.Started(err, javaServer.PID, &javalog.JavaParse{}) }()
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
 	// Start server
-	if err := javaServer.PID.Start(javaServer.ServerStart); err != nil {
+	return javaServer.PID.Start(javaServer.ServerStart)
+}
+
+// Restore backup from [Server.Tar] or [Server.Zip], server must be stopped.
+//
+// Files are restored to server folder,
+// old files are kept until restored server start, if start fail or server exit before old files are restored
+func (javaServer *Server) Restore(r io.Reader, format server.ArchiveFormat) error {
+	if javaServer == nil {
+		return server.ErrNoProc
+	} else if server.Running(javaServer.PID) {
+		return server.ErrRunning
+	}
+
+	target := javaServer.ServerStart.Cwd
+	point, err := server.RestoreArchive(r, format, target, server.LayoutAny("server.properties", "*/level.dat"))
+	if err != nil {
 		return err
 	}
+	javaServer.restored.Wait()
+	javaServer.restored.Commit() // Previous restore not started
+	javaServer.restored = point
 	return nil
 }
 
-// Stop server
-func (javaServer *Server) Stop() error {
-	switch javaServer.stopCalled {
-	case 0:
-		javaServer.stopCalled = 1
-		_, err := javaServer.PID.Write([]byte("stop\n"))
-		return err
-	case 1:
-		javaServer.stopCalled = 2
-		return javaServer.PID.Signal(syscall.SIGINT)
-	default:
-		javaServer.stopCalled = 3
-		return javaServer.PID.Signal(syscall.SIGKILL)
+// Stop server with StopConfig stages ("stop" command, SIGINT and kill),
+// return stage that ended server and exit code
+func (javaServer *Server) Stop(ctx context.Context) (*server.StopStatus, error) {
+	if javaServer == nil || javaServer.PID == nil {
+		return nil, server.ErrNoProc
+	}
+	return server.Stop(ctx, javaServer.PID, javaServer.StopConfig)
+}
+
+// Write command to server and return lines printed in response, command is sent
+// over RCON if enable-rcon is set in server.properties, else write to console.
+// Console is used only if RCON not received command, so command not run twice
+func (javaServer *Server) RunCommand(ctx context.Context, command string) ([]string, error) {
+	if javaServer == nil {
+		return nil, server.ErrNoProc
+	} else if lines, sent, err := javaServer.rconCommand(ctx, command); err == nil || sent || javaServer.PID == nil || ctx.Err() != nil {
+		return lines, err
+	}
+	return server.RunCommand(ctx, javaServer.PID, command, server.CommandOptions{ParseLine: javalog.ParseLine, Terminator: commandEnd})
+}
+
+// Java server point error position with "<--[HERE]" on invalid command
+func commandEnd(line *logs.Line) bool { return strings.HasSuffix(line.Message, "<--[HERE]") }
+
+// Wait server process end
+func (javaServer *Server) Wait() error {
+	if javaServer == nil || javaServer.PID == nil {
+		return server.ErrNoProc
+	}
+	return javaServer.PID.Wait()
+}
+
+// Server process
+func (javaServer *Server) Proc() exec.Proc { return javaServer.PID }
+
+// Server version
+func (javaServer *Server) ServerVersion() string {
+	if javaServer.Version == nil {
+		return ""
 	}
+	return javaServer.Version.Version()
 }
@@ -1,6 +1,7 @@
 package java
 
 import (
+	"context"
 	"fmt"
 	"os"
 	os_exec "os/exec"
@@ -9,6 +10,7 @@ import (
 	"strings"
 
 	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
@@ -70,8 +72,9 @@ func (version SpigotMC) JavaVersion() javaprebuild.JavaVersion {
 
 // Build Spigot localy
 //
-// If InstallPath already has java in the path "InstallPath + bin/java", this version will be used
-func (ver *SpigotMC) Install(InstallPath string) error {
+// If InstallPath already has java in the path "InstallPath + bin/java", this version will be used,
+// progress is only reported to java install
+func (ver *SpigotMC) Install(ctx context.Context, InstallPath string, progress cache.Progress) error {
 	if file_checker.IsFile(filepath.Join(InstallPath, "server.jar")) {
 		return nil
 	}
@@ -89,10 +92,12 @@ func (ver *SpigotMC) Install(InstallPath string) error {
 	BuildTools := filepath.Join(BuildDir, "SpigotBuildTools.jar")
 	if _, err := request.SaveAs(SpigotBuildToolsURL, BuildTools, nil); err != nil {
 		return err
+	} else if err = ctx.Err(); err != nil {
+		return err
 	}
 
 	javaPath := filepath.Join(BuildDir, "java")
-	switch err := ver.JavaVersion().InstallLatest(javaPath); err {
+	switch err := ver.JavaVersion().InstallLatest(ctx, javaPath, progress); err {
 	case nil:
 		if javaPath = filepath.Join(javaPath, "bin/java"); runtime.GOOS == "windows" {
 			javaPath += ".exe"
@@ -127,9 +132,18 @@ func (ver *SpigotMC) Install(InstallPath string) error {
 			buildFlag.Arguments = append(buildFlag.Arguments, "--compile", buildTarget)
 		}
 
-		// Wait process end
-		build.Start(buildFlag)
-		if err := build.Wait(); err != nil {
+		// Wait process end, kill build if context done
+		if err := ctx.Err(); err != nil {
+			return err
+		} else if err = build.Start(buildFlag); err != nil {
+			return err
+		}
+		stop := context.AfterFunc(ctx, func() { build.Kill() })
+		err := build.Wait()
+		if !stop() {
+			os.Remove(filepath.Join(InstallPath, "server.jar")) // Partial build output
+			return ctx.Err()
+		} else if err != nil {
 			return err
 		}
 	}
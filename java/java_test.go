@@ -1,8 +1,31 @@
 package java
 
 import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/java/rcon"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 )
 
 // List versions
@@ -73,3 +96,324 @@ func TestListVersions(t *testing.T) {
 		t.Logf("Velocity versions: %s", d)
 	})
 }
+
+func TestVersionsJSON(t *testing.T) {
+	spigot := &SpigotMC{MCVersion: "1.21.4", JavaVersions: []uint{65, 67}}
+	spigot.Ref.Spigot = "abc"
+	versions := Versions{
+		GenericVersion{ServerVersion: "1.21.4", JVM: 65, DownloadURL: "https://example.com/server.jar", Hash: cache.Hash{Algorithm: cache.SHA1, Sum: "abc"}, ReleaseDate: time.Date(2024, 12, 3, 0, 0, 0, 0, time.UTC)},
+		spigot,
+	}
+	data, err := json.Marshal(versions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Versions
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(decoded, versions) {
+		t.Errorf("versions not decoded:\n%#v\n%#v", decoded, versions)
+	}
+}
+
+func TestInstallHash(t *testing.T) {
+	jar := []byte("server jar")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.Write(jar) }))
+	defer upstream.Close()
+
+	sum := sha1.Sum(jar)
+	version := GenericVersion{ServerVersion: "1.21.4", DownloadURL: upstream.URL + "/server.jar", Hash: cache.Hash{Algorithm: cache.SHA1, Sum: hex.EncodeToString(sum[:])}}
+	folder := t.TempDir()
+	if err := version.Install(context.Background(), folder, nil); err != nil {
+		t.Fatal(err)
+	} else if err = version.Verify(folder); err != nil {
+		t.Errorf("installed jar not valid: %s", err)
+	}
+
+	// Corrupted jar
+	os.WriteFile(filepath.Join(folder, "server.jar"), []byte("corrupted"), 0644)
+	if err := version.Verify(folder); !errors.Is(err, cache.ErrHash) {
+		t.Errorf("expected hash error, got %v", err)
+	}
+
+	// Upstream hash not match
+	version.Hash.Sum = strings.Repeat("0", 40)
+	if err := version.Install(context.Background(), t.TempDir(), nil); !errors.Is(err, cache.ErrHash) {
+		t.Errorf("expected hash error, got %v", err)
+	}
+}
+
+func TestServerProperties(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.properties")
+	data := `#Minecraft server properties
+#Sat Mar 01 12:00:00 BRT 2025
+motd=Go bds
+gamemode=creative
+difficulty=hard
+enable-rcon=true
+rcon.password=secret
+rcon.port=25580
+enable-query=true
+query.port=25566
+simulation-distance=12
+resource-pack=https\://example.com/pack.zip
+snooper-enabled=false
+plugin.chat.format=<name> msg
+`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadServerProperties(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if config.Motd != "Go bds" || config.Gamemode != "creative" || config.Difficulty != "hard" || config.SimulationDistance != 12 {
+		t.Errorf("invalid values: %+v", config)
+	} else if !config.EnableRCON || config.RCON.Password != "secret" || config.RCON.Port != 25580 || !config.EnableQuery || config.Query.Port != 25566 {
+		t.Errorf("invalid rcon/query values: %+v", config)
+	} else if config.ServerPort != 25565 || config.LevelName != "world" {
+		t.Errorf("default values not kept: %+v", config)
+	} else if config.ExtraKeys["snooper-enabled"] != "false" || config.ExtraKeys["plugin.chat.format"] != "<name> msg" {
+		t.Errorf("unknown key not kept: %v", config.ExtraKeys)
+	}
+
+	// Round-trip
+	latest := &GenericVersion{ServerVersion: "1.21.4"}
+	if _, err = config.Save(file, latest); err != nil {
+		t.Fatal(err)
+	}
+	config2, err := LoadServerProperties(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(config, config2) {
+		t.Errorf("round-trip values diverge:\n%+v\n%+v", config, config2)
+	}
+
+	// Keys from new versions
+	if keys := config.Unsupported(&GenericVersion{ServerVersion: "1.17.1"}); !slices.Contains(keys, "simulation-distance") {
+		t.Errorf("simulation-distance not reported to 1.17.1: %v", keys)
+	} else if keys = config.Unsupported(latest); len(keys) > 0 {
+		t.Errorf("keys reported to 1.21.4: %v", keys)
+	}
+	if data, err := config.MarshalProperties(&GenericVersion{ServerVersion: "1.16.5"}); err != nil {
+		t.Error(err)
+	} else if strings.Contains(string(data), "simulation-distance") || !strings.Contains(string(data), "view-distance") {
+		t.Errorf("invalid keys to 1.16.5:\n%s", data)
+	}
+	if skipped, err := config.Save(file, &GenericVersion{ServerVersion: "1.17.1"}); err != nil {
+		t.Error(err)
+	} else if !slices.Equal(skipped, []string{"simulation-distance"}) {
+		t.Errorf("unsupported keys not reported: %v", skipped)
+	} else if data, _ := os.ReadFile(file); !strings.Contains(string(data), "motd = Go bds") || strings.Contains(string(data), "simulation-distance") {
+		t.Errorf("supported keys not writed:\n%s", data)
+	}
+
+	// Old versions defaults
+	old := &GenericVersion{ServerVersion: "1.12.2"}
+	if defaults := DefaultVersionProperties(old); defaults.LevelType != "default" || defaults.Difficulty != "1" || defaults.Gamemode != "0" {
+		t.Errorf("invalid defaults to 1.12.2: %+v", defaults)
+	} else if skipped, err := defaults.Save(file, old); err != nil || len(skipped) > 0 {
+		t.Errorf("cannot save defaults to 1.12.2: %v, %v", skipped, err)
+	}
+
+	config.SimulationDistance, config.Gamemode = 64, "hardcore"
+	if err = config.Validate(); !errors.Is(err, ErrInvalidProperty) || !strings.Contains(err.Error(), "simulation-distance") || !strings.Contains(err.Error(), "gamemode") {
+		t.Errorf("invalid values not reported: %v", err)
+	}
+}
+
+type stubResolver map[string]string
+
+func (stub stubResolver) Resolve(_ context.Context, name string) (PlayerID, error) {
+	if uuid, ok := stub[name]; ok {
+		return PlayerID{UUID: uuid, Name: name}, nil
+	}
+	return PlayerID{}, ErrUUID
+}
+
+func TestPlayerLists(t *testing.T) {
+	ctx := context.Background()
+	javaServer := &Server{ServerStart: exec.ProcExec{Cwd: t.TempDir()}, UUIDResolver: stubResolver{"Notch": "069a79f4-44e9-4726-a5be-fca90e38aaf5"}}
+
+	// Online mode
+	if err := javaServer.Op(ctx, "Notch", 4, true); err != nil {
+		t.Fatal(err)
+	} else if err = javaServer.Op(ctx, "Herobrine", 4, false); !errors.Is(err, ErrUUID) {
+		t.Errorf("expected resolver error: %v", err)
+	}
+	if ops, err := javaServer.Ops(); err != nil {
+		t.Fatal(err)
+	} else if len(ops) != 1 || ops[0].UUID != "069a79f4-44e9-4726-a5be-fca90e38aaf5" || ops[0].Level != 4 || !ops[0].BypassesPlayerLimit {
+		t.Errorf("invalid ops.json: %+v", ops)
+	}
+
+	// Offline mode
+	config := DefaultServerProperties()
+	config.OnlineMode = false
+	if _, err := config.Save(filepath.Join(javaServer.ServerStart.Cwd, "server.properties"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if player, err := javaServer.ResolvePlayer(ctx, "Notch"); err != nil {
+		t.Fatal(err)
+	} else if player.UUID != "b50ad385-829d-3141-a216-7e7d7539ba7f" {
+		t.Errorf("invalid offline uuid: %s", player.UUID)
+	}
+
+	if err := javaServer.WhitelistAdd(ctx, "Herobrine"); err != nil {
+		t.Fatal(err)
+	} else if err = javaServer.WhitelistAdd(ctx, "Steve"); err != nil {
+		t.Fatal(err)
+	} else if err = javaServer.WhitelistRemove(ctx, "steve"); err != nil {
+		t.Fatal(err)
+	}
+	if list, err := javaServer.Whitelist(); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "Herobrine" || list[0].UUID != OfflineUUID("Herobrine") {
+		t.Errorf("invalid whitelist.json: %+v", list)
+	}
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := javaServer.Ban(ctx, "Herobrine", "griefing", expires); err != nil {
+		t.Fatal(err)
+	} else if err = javaServer.BanIP(ctx, "10.0.0.2", "", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if bans, err := javaServer.BannedPlayers(); err != nil {
+		t.Fatal(err)
+	} else if len(bans) != 1 || bans[0].Reason != "griefing" || !bans[0].Expires.Equal(expires) {
+		t.Errorf("invalid banned-players.json: %+v", bans)
+	}
+	data, _ := os.ReadFile(filepath.Join(javaServer.ServerStart.Cwd, "banned-ips.json"))
+	if !strings.Contains(string(data), `"expires": "forever"`) {
+		t.Errorf("invalid banned-ips.json: %s", data)
+	} else if err := javaServer.PardonIP(ctx, "10.0.0.2"); err != nil {
+		t.Error(err)
+	} else if err = javaServer.Pardon(ctx, "Steve"); !errors.Is(err, logs.ErrPlayerNotExist) {
+		t.Errorf("expected player not exists: %v", err)
+	}
+}
+
+func TestHotBackup(t *testing.T) {
+	cwd := t.TempDir()
+	for _, name := range []string{"world/level.dat", "world/region/r.0.0.mca", "world_nether/DIM-1/region/r.0.0.mca", "world/session.lock", "plugins/test.jar", "logs/latest.log", "server.properties"} {
+		os.MkdirAll(filepath.Join(cwd, filepath.Dir(name)), 0755)
+		if err := os.WriteFile(filepath.Join(cwd, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	javaServer := &Server{ServerStart: exec.ProcExec{Cwd: cwd}}
+	var buff bytes.Buffer
+	report, err := javaServer.HotTar(context.Background(), &buff, BackupOptions{WorldsOnly: true, Include: []string{"plugins", "config"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(report.Files)
+	expected := []string{"plugins/test.jar", "world/level.dat", "world/region/r.0.0.mca", "world_nether/DIM-1/region/r.0.0.mca"}
+	if !slices.Equal(report.Files, expected) || len(report.Changed) != 0 {
+		t.Errorf("invalid report: %+v", report)
+	}
+
+	reader, files := tar.NewReader(&buff), []string{}
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		files = append(files, header.Name)
+	}
+	if slices.Sort(files); !slices.Equal(files, expected) {
+		t.Errorf("invalid tar files: %v", files)
+	}
+}
+
+// Console that print response to each command in log4j format
+type consoleProc struct {
+	exec.Proc
+	stdin  strings.Builder
+	stdout exec.Writers
+}
+
+func (proc *consoleProc) Write(p []byte) (int, error) {
+	proc.stdin.Write(p)
+	go proc.stdout.Write([]byte("[12:00:00] [Server thread/INFO]: console " + string(p)))
+	return len(p), nil
+}
+
+func (proc *consoleProc) StdoutFork() (io.ReadCloser, error) {
+	r, w := io.Pipe()
+	proc.stdout.AddNewWriter(w)
+	return r, nil
+}
+
+func TestRunCommandRCON(t *testing.T) {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dials atomic.Int32
+	conns := make(chan net.Conn, 10)
+	go func() {
+		for {
+			conn, err := listen.Accept()
+			if err != nil {
+				return
+			}
+			dials.Add(1)
+			conns <- conn
+			go func() {
+				defer conn.Close()
+				for {
+					packet, err := rcon.ReadPacket(conn)
+					if err != nil {
+						return
+					}
+					response := rcon.Packet{ID: packet.ID, Type: rcon.TypeAuthResponse}
+					if packet.Type == rcon.TypeCommand {
+						response = rcon.Packet{ID: packet.ID, Type: rcon.TypeResponse, Body: "rcon " + packet.Body}
+					}
+					data, _ := response.MarshalBinary()
+					conn.Write(data)
+				}
+			}()
+		}
+	}()
+
+	cwd := t.TempDir()
+	port := listen.Addr().(*net.TCPAddr).Port
+	os.WriteFile(filepath.Join(cwd, "server.properties"), fmt.Appendf(nil, "enable-rcon=true\nrcon.port=%d\nrcon.password=secret\n", port), 0644)
+	proc := &consoleProc{}
+	javaServer := &Server{PID: proc, ServerStart: exec.ProcExec{Cwd: cwd}}
+
+	// Commands use same RCON connection
+	for range 3 {
+		if lines, err := javaServer.RunCommand(context.Background(), "list"); err != nil {
+			t.Fatal(err)
+		} else if !slices.Equal(lines, []string{"rcon list"}) {
+			t.Errorf("invalid rcon response: %q", lines)
+		}
+	}
+	if dials.Load() != 1 {
+		t.Errorf("expected one rcon connection, got %d", dials.Load())
+	}
+
+	// Server closed RCON after command sent, command not sent again to console
+	listen.Close()
+	close(conns)
+	for conn := range conns {
+		conn.Close()
+	}
+	if _, err := javaServer.RunCommand(context.Background(), "give Steve diamond"); err == nil {
+		t.Error("expected error from closed connection")
+	} else if proc.stdin.String() != "" {
+		t.Errorf("command sent again to console: %q", proc.stdin.String())
+	}
+
+	// RCON cannot connect, fallback to console
+	if lines, err := javaServer.RunCommand(context.Background(), "list"); err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(lines, []string{"console list"}) || proc.stdin.String() != "list\n" {
+		t.Errorf("command not sent to console: %q, stdin %q", lines, proc.stdin.String())
+	}
+}
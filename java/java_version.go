@@ -1,11 +1,16 @@
 package java
 
 import (
+	"context"
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/javaprebuild"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
 	"sirherobrine23.com.br/go-bds/request/v2"
@@ -13,28 +18,106 @@ import (
 
 // Version info to server
 type Version interface {
-	Version() string                       // Server version string
-	Install(folder string) error           // Install server to folder path
-	JavaVersion() javaprebuild.JavaVersion // Return java version
+	Version() string                                                           // Server version string
+	Install(ctx context.Context, folder string, progress cache.Progress) error // Install server to folder path, progress can be nil
+	JavaVersion() javaprebuild.JavaVersion                                     // Return java version
 }
 
 type GenericVersion struct {
-	ServerVersion string
-	JVM           javaprebuild.JavaVersion
-	DownloadURL   string
+	ServerVersion string                   `json:"version"`        // Server version
+	JVM           javaprebuild.JavaVersion `json:"java"`           // Java major version to run server
+	DownloadURL   string                   `json:"url"`            // Server jar url
+	Hash          cache.Hash               `json:"hash,omitzero"`  // Server jar hash from upstream, empty if upstream not provide
+	ReleaseDate   time.Time                `json:"releaseDate"`    // Server release or build date
+	Proxy         bool                     `json:"proxy,omitzero"` // Proxy server without server.properties, example Velocity
+}
+
+// Version is proxy server like Velocity, proxies not use server.properties
+func IsProxy(version Version) bool {
+	generic, ok := version.(GenericVersion)
+	return ok && generic.Proxy
 }
 
 func (v GenericVersion) Version() string                       { return v.ServerVersion }
 func (v GenericVersion) JavaVersion() javaprebuild.JavaVersion { return v.JVM }
-func (v GenericVersion) Install(folder string) error {
-	_, err := request.SaveAs(v.DownloadURL, filepath.Join(folder, "server.jar"), nil)
-	return err
+
+// Install server.jar and check upstream hash, fail with [cache.ErrHash] if not match
+func (v GenericVersion) Install(ctx context.Context, folder string, progress cache.Progress) error {
+	return cache.Default.SaveAs(ctx, v.DownloadURL, v.Hash, filepath.Join(folder, "server.jar"), nil, progress)
+}
+
+// Check installed server.jar with upstream hash, return [cache.ErrHash] if file is corrupted.
+// Versions without hash are not checked
+func (v GenericVersion) Verify(folder string) error {
+	if v.Hash.Sum == "" {
+		return nil
+	}
+	file, err := os.Open(filepath.Join(folder, "server.jar"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return v.Hash.Verify(file)
 }
 
 // Versions is a list of Version
 type Versions []Version
 
-func pistonInfoWorker(versions *Versions, job <-chan *mojangVersion, errPtr *error, wg *sync.WaitGroup) {
+// Decode versions saved with [encoding/json], elements with "refs" are [*SpigotMC]
+// and others [GenericVersion]
+func (vers *Versions) UnmarshalJSON(data []byte) error {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+
+	*vers = make(Versions, 0, len(elements))
+	for _, element := range elements {
+		var kind struct {
+			Refs json.RawMessage `json:"refs"`
+		}
+		if err := json.Unmarshal(element, &kind); err != nil {
+			return err
+		}
+		var ver Version = &GenericVersion{}
+		if kind.Refs != nil {
+			ver = &SpigotMC{}
+		}
+		if err := json.Unmarshal(element, ver); err != nil {
+			return err
+		}
+		if generic, ok := ver.(*GenericVersion); ok {
+			ver = *generic
+		}
+		*vers = append(*vers, ver)
+	}
+	return nil
+}
+
+// Versions already fetched by version name, entries without Hash are from
+// catalogs saved before hashes and are fetched again if upstream have hash
+func (vers Versions) known() map[string]GenericVersion {
+	known := map[string]GenericVersion{}
+	for _, ver := range vers {
+		if generic, ok := ver.(GenericVersion); ok {
+			known[generic.ServerVersion] = generic
+		}
+	}
+	return known
+}
+
+// Replace version with same name or append new version
+func (vers *Versions) set(locker *sync.Mutex, ver Version) {
+	locker.Lock()
+	defer locker.Unlock()
+	if index := slices.IndexFunc(*vers, func(old Version) bool { return old.Version() == ver.Version() }); index >= 0 {
+		(*vers)[index] = ver
+		return
+	}
+	*vers = append(*vers, ver)
+}
+
+func pistonInfoWorker(versions *Versions, job <-chan *mojangVersion, errPtr *error, locker *sync.Mutex, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for data := range job {
 		if semver.New(data.ID) == nil {
@@ -47,34 +130,39 @@ func pistonInfoWorker(versions *Versions, job <-chan *mojangVersion, errPtr *err
 			continue
 		}
 		if serverURL, ok := data.ReleaseInfo.Downloads["server"]; ok {
-			*versions = append(*versions, GenericVersion{
+			versions.set(locker, GenericVersion{
 				ServerVersion: data.ID,
 				DownloadURL:   serverURL.URL,
+				Hash:          cache.Hash{Algorithm: cache.SHA1, Sum: serverURL.Sha1},
 				JVM:           javaprebuild.JavaVersion(data.ReleaseInfo.JavaVersion.MajorVersion + 44),
+				ReleaseDate:   data.ReleaseTime,
 			})
 		}
 	}
 }
 
-// Fetch servers from Mojang servers
+// Fetch servers from Mojang servers and append new versions,
+// versions already in slice with hash are not requested again
 func (versions *Versions) FetchMojang() (err error) {
 	data, _, err := request.JSON[mojangPistonVersion]("https://piston-meta.mojang.com/mc/game/version_manifest_v2.json", nil)
 	if err != nil {
 		return err
 	}
 
-	// Clean slice
-	*versions = (*versions)[:0]
+	known := versions.known()
 	var wg sync.WaitGroup
+	var locker sync.Mutex
 	jobs := make(chan *mojangVersion)
 	for range runtime.NumCPU() * 2 {
 		wg.Add(1)
-		go pistonInfoWorker(versions, jobs, &err, &wg)
+		go pistonInfoWorker(versions, jobs, &err, &locker, &wg)
 	}
 
 	// Send job to workers
-	for _, versions := range data.Versions {
-		jobs <- versions
+	for _, version := range data.Versions {
+		if old, ok := known[version.ID]; !ok || old.Hash.Sum == "" {
+			jobs <- version
+		}
 	}
 
 	// Done sending jobs
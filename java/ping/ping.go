@@ -0,0 +1,272 @@
+// Minecraft Java server status with Server List Ping (1.7+), legacy ping (1.4 to 1.6) and GameSpy4 Query
+//
+// Protocol reference: https://minecraft.wiki/w/Java_Edition_protocol/Server_List_Ping and https://minecraft.wiki/w/Query
+package ping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPort      = 25565 // Default Java server port
+	DefaultQueryPort = 25565 // Default query port, same of server port
+	maxPacket        = 2 << 20
+)
+
+var (
+	ErrPacket error = errors.New("ping: invalid packet") // Server response not is valid packet
+	ErrVarInt error = errors.New("ping: varint too big") // VarInt larger than 5 bytes
+
+	DefaultTimeout = 5 * time.Second // Default time to wait server response if ctx not have deadline
+)
+
+// Player from status sample
+type Player struct {
+	Name string `json:"name"` // Player username
+	ID   string `json:"id"`   // Player UUID
+}
+
+// Server MOTD, server can send plain string or chat component
+type Description struct {
+	Text string          `json:"-"` // Text without formatting
+	Raw  json.RawMessage `json:"-"` // Original chat component
+}
+
+func (desc Description) String() string { return desc.Text }
+func (desc Description) MarshalJSON() ([]byte, error) {
+	if len(desc.Raw) > 0 {
+		return desc.Raw, nil
+	}
+	return json.Marshal(desc.Text)
+}
+func (desc *Description) UnmarshalJSON(data []byte) error {
+	var component any
+	if err := json.Unmarshal(data, &component); err != nil {
+		return err
+	}
+	desc.Raw, desc.Text = append(json.RawMessage(nil), data...), componentText(component)
+	return nil
+}
+
+// Join text from chat component and extra components
+func componentText(component any) string {
+	switch value := component.(type) {
+	case string:
+		return value
+	case []any:
+		text := ""
+		for _, extra := range value {
+			text += componentText(extra)
+		}
+		return text
+	case map[string]any:
+		text := ""
+		if value, ok := value["text"].(string); ok {
+			text = value
+		}
+		if extra, ok := value["extra"]; ok {
+			text += componentText(extra)
+		}
+		return text
+	}
+	return ""
+}
+
+// Server status
+type Status struct {
+	Version struct {
+		Name     string `json:"name"`     // Server version name, example "1.21.4" or "Paper 1.21.4"
+		Protocol int    `json:"protocol"` // Protocol version
+	} `json:"version"`
+	Players struct {
+		Max    int      `json:"max"`              // Max players
+		Online int      `json:"online"`           // Players online
+		Sample []Player `json:"sample,omitempty"` // Some players online
+	} `json:"players"`
+	Description        Description   `json:"description"`                  // Server MOTD
+	Favicon            string        `json:"favicon,omitempty"`            // PNG image encoded in data url
+	EnforcesSecureChat bool          `json:"enforcesSecureChat,omitempty"` // Server require signed chat
+	Latency            time.Duration `json:"-"`                            // Ping latency
+	Legacy             bool          `json:"-"`                            // Status from legacy ping
+}
+
+// Get server status with Server List Ping and fallback to legacy ping
+// if server not reply, address port is optional and default is [DefaultPort]
+func Ping(ctx context.Context, address string) (*Status, error) {
+	status, err := PingStatus(ctx, address)
+	if err == nil || ctx.Err() != nil {
+		return status, err
+	}
+
+	// Try legacy ping if server close connection or send invalid packet
+	if legacyStatus, legacyErr := PingLegacy(ctx, address); legacyErr == nil {
+		return legacyStatus, nil
+	}
+	return nil, err
+}
+
+// Get status with Server List Ping, to servers 1.7 and above
+func PingStatus(ctx context.Context, address string) (*Status, error) {
+	host, port, address := splitAddress(address, DefaultPort)
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// Handshake with next state to status and status request
+	handshake := &bytes.Buffer{}
+	writeVarInt(handshake, 0x00)
+	writeVarInt(handshake, -1)
+	writeString(handshake, host)
+	binary.Write(handshake, binary.BigEndian, uint16(port))
+	writeVarInt(handshake, 1)
+	if err = writePacket(conn, handshake.Bytes()); err != nil {
+		return nil, err
+	} else if err = writePacket(conn, []byte{0x00}); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	packet, err := readPacket(reader)
+	if err != nil {
+		return nil, err
+	} else if id, _ := readVarInt(packet); id != 0x00 {
+		return nil, fmt.Errorf("%w: status id %d", ErrPacket, id)
+	}
+	body, err := readString(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{}
+	if err = json.Unmarshal([]byte(body), status); err != nil {
+		return nil, err
+	}
+
+	// Ping and pong to latency
+	payload, started := time.Now().UnixMilli(), time.Now()
+	ping := &bytes.Buffer{}
+	writeVarInt(ping, 0x01)
+	binary.Write(ping, binary.BigEndian, payload)
+	if err = writePacket(conn, ping.Bytes()); err != nil {
+		return status, nil // Some servers close connection after status
+	}
+	if packet, err = readPacket(reader); err == nil {
+		var pong int64
+		if id, _ := readVarInt(packet); id == 0x01 && binary.Read(packet, binary.BigEndian, &pong) == nil && pong == payload {
+			status.Latency = time.Since(started)
+		}
+	}
+	return status, nil
+}
+
+// Add default port if not set and return host, port and address
+func splitAddress(address string, defaultPort int) (string, int, string) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		host, portStr = strings.Trim(address, "[]"), strconv.Itoa(defaultPort)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port == 0 {
+		port = defaultPort
+	}
+	return host, port, net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Dial and set deadline from ctx or [DefaultTimeout]
+func dial(ctx context.Context, network, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(DefaultTimeout)
+	}
+	conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+func writeVarInt(w io.ByteWriter, value int32) {
+	unsigned := uint32(value)
+	for {
+		if unsigned&^0x7F == 0 {
+			w.WriteByte(byte(unsigned))
+			return
+		}
+		w.WriteByte(byte(unsigned&0x7F | 0x80))
+		unsigned >>= 7
+	}
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var value uint32
+	for position := 0; ; position += 7 {
+		if position >= 35 {
+			return 0, ErrVarInt
+		}
+		current, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint32(current&0x7F) << position
+		if current&0x80 == 0 {
+			return int32(value), nil
+		}
+	}
+}
+
+func writeString(w *bytes.Buffer, value string) {
+	writeVarInt(w, int32(len(value)))
+	w.WriteString(value)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	size, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	} else if size < 0 || int(size) > r.Len() {
+		return "", fmt.Errorf("%w: string size %d", ErrPacket, size)
+	}
+	data := make([]byte, size)
+	_, err = io.ReadFull(r, data)
+	return string(data), err
+}
+
+// Write packet with VarInt length prefix
+func writePacket(w io.Writer, data []byte) error {
+	packet := &bytes.Buffer{}
+	writeVarInt(packet, int32(len(data)))
+	packet.Write(data)
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+// Read packet with VarInt length prefix
+func readPacket(r *bufio.Reader) (*bytes.Reader, error) {
+	size, err := readVarInt(r)
+	if err != nil {
+		return nil, err
+	} else if size <= 0 || size > maxPacket {
+		return nil, fmt.Errorf("%w: size %d", ErrPacket, size)
+	}
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
@@ -0,0 +1,97 @@
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Get status with legacy ping used by servers 1.4 to 1.6,
+// servers before 1.4 reply only MOTD and players count
+func PingLegacy(ctx context.Context, address string) (*Status, error) {
+	host, port, address := splitAddress(address, DefaultPort)
+	conn, err := dial(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 0xFE 0x01 0xFA "MC|PingHost" with protocol, host and port
+	hostData := &bytes.Buffer{}
+	hostData.WriteByte(0x4A) // 1.6.2 protocol
+	writeUTF16(hostData, host)
+	binary.Write(hostData, binary.BigEndian, int32(port))
+
+	request := &bytes.Buffer{}
+	request.Write([]byte{0xFE, 0x01, 0xFA})
+	writeUTF16(request, "MC|PingHost")
+	binary.Write(request, binary.BigEndian, uint16(hostData.Len()))
+	request.Write(hostData.Bytes())
+
+	started := time.Now()
+	if _, err = conn.Write(request.Bytes()); err != nil {
+		return nil, err
+	}
+
+	var header [3]byte
+	if _, err = io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	} else if header[0] != 0xFF {
+		return nil, fmt.Errorf("%w: legacy kick id %x", ErrPacket, header[0])
+	}
+	latency := time.Since(started)
+
+	data := make([]byte, int(binary.BigEndian.Uint16(header[1:]))*2)
+	if _, err = io.ReadFull(conn, data); err != nil {
+		return nil, err
+	}
+	chars := make([]uint16, len(data)/2)
+	for index := range chars {
+		chars[index] = binary.BigEndian.Uint16(data[index*2:])
+	}
+
+	status, err := parseLegacy(string(utf16.Decode(chars)))
+	if err != nil {
+		return nil, err
+	}
+	status.Latency = latency
+	return status, nil
+}
+
+// Parse legacy kick message
+//
+//	1.4 to 1.6: "§1\x00127\x001.6.4\x00A Minecraft Server\x000\x0020"
+//	Before 1.4: "A Minecraft Server§0§20"
+func parseLegacy(text string) (*Status, error) {
+	status := &Status{Legacy: true}
+	if fields := strings.Split(text, "\x00"); len(fields) == 6 && fields[0] == "§1" {
+		status.Version.Protocol, _ = strconv.Atoi(fields[1])
+		status.Version.Name = fields[2]
+		status.Description.Text = fields[3]
+		status.Players.Online, _ = strconv.Atoi(fields[4])
+		status.Players.Max, _ = strconv.Atoi(fields[5])
+		return status, nil
+	}
+
+	fields := strings.Split(text, "§")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("%w: legacy response %q", ErrPacket, text)
+	}
+	status.Description.Text = strings.Join(fields[:len(fields)-2], "§")
+	status.Players.Online, _ = strconv.Atoi(fields[len(fields)-2])
+	status.Players.Max, _ = strconv.Atoi(fields[len(fields)-1])
+	return status, nil
+}
+
+// Write string with length in chars and UTF-16BE encoded
+func writeUTF16(w *bytes.Buffer, value string) {
+	chars := utf16.Encode([]rune(value))
+	binary.Write(w, binary.BigEndian, uint16(len(chars)))
+	binary.Write(w, binary.BigEndian, chars)
+}
@@ -0,0 +1,50 @@
+package ping
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"sirherobrine23.com.br/go-bds/go-bds/utils/properties"
+)
+
+// Ports keys from server.properties
+type serverProperties struct {
+	ServerIP    string `properties:"server-ip"`
+	ServerPort  int    `properties:"server-port"`
+	EnableQuery bool   `properties:"enable-query"`
+	Query       struct {
+		Port int `properties:"port"`
+	} `properties:"query"`
+}
+
+// Read server.properties and return address to [Ping] and [QueryFullStat],
+// query is blank if enable-query is false and localhost is used if server-ip is blank
+func AddressFromProperties(file string) (server, query string, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", "", err
+	}
+
+	var config serverProperties
+	if err = properties.Unmarshal(data, &config); err != nil {
+		return "", "", err
+	}
+
+	host := config.ServerIP
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if config.ServerPort == 0 {
+		config.ServerPort = DefaultPort
+	}
+	server = net.JoinHostPort(host, strconv.Itoa(config.ServerPort))
+
+	if config.EnableQuery {
+		if config.Query.Port == 0 {
+			config.Query.Port = config.ServerPort
+		}
+		query = net.JoinHostPort(host, strconv.Itoa(config.Query.Port))
+	}
+	return server, query, nil
+}
@@ -0,0 +1,159 @@
+package ping
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+)
+
+const (
+	queryHandshake byte = 0x09
+	queryStat      byte = 0x00
+)
+
+var queryMagic = []byte{0xFE, 0xFD}
+
+// Basic stat from query
+type QueryBasic struct {
+	MOTD     string `json:"motd"`      // Server MOTD
+	GameType string `json:"game_type"` // Always "SMP"
+	Map      string `json:"map"`       // World name
+	Online   int    `json:"online"`    // Players online
+	Max      int    `json:"max"`       // Max players
+	Port     int    `json:"port"`      // Server port
+	IP       string `json:"ip"`        // Server ip
+}
+
+// Full stat from query
+type QueryFull struct {
+	QueryBasic
+	GameID  string            `json:"game_id"` // Always "MINECRAFT"
+	Version string            `json:"version"` // Server version
+	Plugins string            `json:"plugins"` // Server software and plugins, example "Paper on 1.21.4: WorldEdit 7.3.0; Vault 1.7.3"
+	Players []string          `json:"players"` // Players username online
+	Values  map[string]string `json:"values"`  // All keys returned by server
+}
+
+// Get basic stat with GameSpy4 Query, address port is optional and default is [DefaultQueryPort]
+func QueryBasicStat(ctx context.Context, address string) (*QueryBasic, error) {
+	response, err := query(ctx, address, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := bytes.SplitN(response, []byte{0}, 6)
+	if len(fields) != 6 || len(fields[5]) < 2 {
+		return nil, fmt.Errorf("%w: basic stat", ErrPacket)
+	}
+
+	basic := &QueryBasic{
+		MOTD:     string(fields[0]),
+		GameType: string(fields[1]),
+		Map:      string(fields[2]),
+		Port:     int(binary.LittleEndian.Uint16(fields[5])),
+		IP:       string(bytes.TrimRight(fields[5][2:], "\x00")),
+	}
+	basic.Online, _ = strconv.Atoi(string(fields[3]))
+	basic.Max, _ = strconv.Atoi(string(fields[4]))
+	return basic, nil
+}
+
+// Get full stat with GameSpy4 Query, address port is optional and default is [DefaultQueryPort]
+func QueryFullStat(ctx context.Context, address string) (*QueryFull, error) {
+	response, err := query(ctx, address, true)
+	if err != nil {
+		return nil, err
+	} else if len(response) < 11 {
+		return nil, fmt.Errorf("%w: full stat", ErrPacket)
+	}
+	response = response[11:] // "splitnum\x00\x80\x00"
+
+	full := &QueryFull{Values: map[string]string{}, Players: []string{}}
+	for {
+		key, rest, ok := bytes.Cut(response, []byte{0})
+		if !ok {
+			return nil, fmt.Errorf("%w: full stat keys", ErrPacket)
+		} else if response = rest; len(key) == 0 {
+			break
+		}
+
+		value, rest, ok := bytes.Cut(response, []byte{0})
+		if !ok {
+			return nil, fmt.Errorf("%w: full stat values", ErrPacket)
+		}
+		response, full.Values[string(key)] = rest, string(value)
+	}
+
+	// "\x01player_\x00\x00" and players terminated with empty name
+	if len(response) >= 10 {
+		for _, name := range bytes.Split(response[10:], []byte{0}) {
+			if len(name) == 0 {
+				break
+			}
+			full.Players = append(full.Players, string(name))
+		}
+	}
+
+	full.MOTD = full.Values["hostname"]
+	full.GameType = full.Values["gametype"]
+	full.GameID = full.Values["game_id"]
+	full.Version = full.Values["version"]
+	full.Plugins = full.Values["plugins"]
+	full.Map = full.Values["map"]
+	full.IP = full.Values["hostip"]
+	full.Online, _ = strconv.Atoi(full.Values["numplayers"])
+	full.Max, _ = strconv.Atoi(full.Values["maxplayers"])
+	full.Port, _ = strconv.Atoi(full.Values["hostport"])
+	return full, nil
+}
+
+// Make handshake and request stat, return payload after session id
+func query(ctx context.Context, address string, full bool) ([]byte, error) {
+	_, _, address = splitAddress(address, DefaultQueryPort)
+	conn, err := dial(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	session := rand.Int32() & 0x0F0F0F0F
+	challenge, err := queryRequest(conn, queryHandshake, session, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := strconv.ParseInt(string(bytes.TrimRight(challenge, "\x00")), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: challenge token %q", ErrPacket, challenge)
+	}
+
+	payload := binary.BigEndian.AppendUint32(nil, uint32(token))
+	if full {
+		payload = append(payload, 0, 0, 0, 0)
+	}
+	return queryRequest(conn, queryStat, session, payload)
+}
+
+// Send query packet and return response payload
+func queryRequest(conn net.Conn, packetType byte, session int32, payload []byte) ([]byte, error) {
+	request := append([]byte{}, queryMagic...)
+	request = append(request, packetType)
+	request = binary.BigEndian.AppendUint32(request, uint32(session))
+	if _, err := conn.Write(append(request, payload...)); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, 65535)
+	for {
+		n, err := conn.Read(response)
+		if err != nil {
+			return nil, err
+		} else if n < 5 || response[0] != packetType || int32(binary.BigEndian.Uint32(response[1:5])) != session {
+			continue // Ignore old responses
+		}
+		return response[5:n], nil
+	}
+}
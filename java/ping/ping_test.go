@@ -0,0 +1,153 @@
+package ping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+const statusJSON = `{"version":{"name":"1.21.4","protocol":769},"players":{"max":20,"online":1,"sample":[{"name":"Sirherobrine23","id":"4566e69f-c907-48ee-8d71-d7ba5aa00d20"}]},"description":{"text":"A ","extra":[{"text":"Minecraft Server","color":"green"}]}}`
+
+// Start fake tcp server with handler to each connection
+func fakeTCP(t *testing.T, handler func(conn net.Conn)) string {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listen.Close() })
+	go func() {
+		for {
+			conn, err := listen.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return listen.Addr().String()
+}
+
+// Fake 1.7+ server
+func modernServer(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	if _, err := readPacket(reader); err != nil { // Handshake
+		return
+	} else if _, err = readPacket(reader); err != nil { // Status request
+		return
+	}
+
+	status := &bytes.Buffer{}
+	writeVarInt(status, 0x00)
+	writeString(status, statusJSON)
+	writePacket(conn, status.Bytes())
+
+	if ping, err := readPacket(reader); err == nil {
+		data, _ := io.ReadAll(ping)
+		writePacket(conn, data)
+	}
+}
+
+// Fake 1.6 server, close connection if not receive legacy ping
+func legacyServer(conn net.Conn) {
+	var header [3]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil || header[0] != 0xFE {
+		return
+	}
+
+	chars := utf16.Encode([]rune("§1\x0078\x001.6.4\x00A Minecraft Server\x002\x0020"))
+	response := &bytes.Buffer{}
+	response.WriteByte(0xFF)
+	binary.Write(response, binary.BigEndian, uint16(len(chars)))
+	binary.Write(response, binary.BigEndian, chars)
+	conn.Write(response.Bytes())
+}
+
+func TestPing(t *testing.T) {
+	status, err := Ping(context.Background(), fakeTCP(t, modernServer))
+	if err != nil {
+		t.Fatal(err)
+	} else if status.Legacy || status.Version.Name != "1.21.4" || status.Version.Protocol != 769 {
+		t.Errorf("invalid version: %+v", status.Version)
+	} else if status.Players.Online != 1 || len(status.Players.Sample) != 1 || status.Players.Sample[0].Name != "Sirherobrine23" {
+		t.Errorf("invalid players: %+v", status.Players)
+	} else if status.Description.Text != "A Minecraft Server" {
+		t.Errorf("invalid description: %q", status.Description.Text)
+	}
+
+	if status, err = Ping(context.Background(), fakeTCP(t, legacyServer)); err != nil {
+		t.Fatal(err)
+	} else if !status.Legacy || status.Version.Name != "1.6.4" || status.Players.Online != 2 || status.Players.Max != 20 {
+		t.Errorf("invalid legacy status: %+v", status)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buff := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buff)
+			if err != nil {
+				return
+			}
+			request := buff[:n]
+			response := append([]byte{request[2]}, request[3:7]...)
+			switch {
+			case request[2] == queryHandshake:
+				response = append(response, "9513307\x00"...)
+			case n == 15: // Full stat
+				response = append(response, "splitnum\x00\x80\x00"...)
+				response = append(response, "hostname\x00A Minecraft Server\x00gametype\x00SMP\x00game_id\x00MINECRAFT\x00version\x001.21.4\x00plugins\x00\x00map\x00world\x00numplayers\x002\x00maxplayers\x0020\x00hostport\x0025565\x00hostip\x00127.0.0.1\x00\x00"...)
+				response = append(response, "\x01player_\x00\x00Sirherobrine23\x00Steve\x00\x00"...)
+			default: // Basic stat
+				response = append(response, "A Minecraft Server\x00SMP\x00world\x002\x0020\x00"...)
+				response = binary.LittleEndian.AppendUint16(response, 25565)
+				response = append(response, "127.0.0.1\x00"...)
+			}
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	basic, err := QueryBasicStat(context.Background(), conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	} else if basic.MOTD != "A Minecraft Server" || basic.Online != 2 || basic.Max != 20 || basic.Port != 25565 || basic.IP != "127.0.0.1" {
+		t.Errorf("invalid basic stat: %+v", basic)
+	}
+
+	full, err := QueryFullStat(context.Background(), conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	} else if full.Version != "1.21.4" || full.Online != 2 || full.Map != "world" {
+		t.Errorf("invalid full stat: %+v", full)
+	} else if len(full.Players) != 2 || full.Players[0] != "Sirherobrine23" || full.Players[1] != "Steve" {
+		t.Errorf("invalid players: %q", full.Players)
+	}
+}
+
+func TestAddressFromProperties(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "server.properties")
+	os.WriteFile(file, []byte("server-ip=\nserver-port=25570\nenable-query=true\nquery.port=25580\n"), 0644)
+
+	server, query, err := AddressFromProperties(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if server != "127.0.0.1:25570" || query != "127.0.0.1:25580" {
+		t.Errorf("invalid address: %q %q", server, query)
+	}
+}
@@ -0,0 +1,214 @@
+// Source RCON protocol client to send commands to Java servers over TCP
+//
+// Protocol reference: https://minecraft.wiki/w/RCON
+package rcon
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TypeResponse     int32 = 0 // SERVERDATA_RESPONSE_VALUE
+	TypeCommand      int32 = 2 // SERVERDATA_EXECCOMMAND
+	TypeAuthResponse int32 = 2 // SERVERDATA_AUTH_RESPONSE
+	TypeAuth         int32 = 3 // SERVERDATA_AUTH
+
+	MaxCommand  = 1446 // Max command length accepted by Minecraft server
+	MaxResponse = 4096 // Max response body in one packet, larger responses are split in many packets
+	MaxPacket   = 4110 // Max packet size with header
+
+	DefaultPort = 25575 // Default RCON port
+)
+
+var (
+	ErrAuth    error = errors.New("rcon: invalid password") // Server rejected password
+	ErrCommand error = errors.New("rcon: command too long") // Command larger than [MaxCommand]
+	ErrPacket  error = errors.New("rcon: invalid packet")   // Packet size or struct invalid
+	ErrSend    error = errors.New("rcon: command not sent") // Write fail, server not received command
+
+	DefaultTimeout = 10 * time.Second // Default time to wait command response
+)
+
+// RCON packet
+type Packet struct {
+	ID   int32  // Request ID, response packets have same ID of request
+	Type int32  // Packet type
+	Body string // Command or response
+}
+
+func (packet Packet) MarshalBinary() ([]byte, error) {
+	buff := bytes.NewBuffer(make([]byte, 0, 14+len(packet.Body)))
+	binary.Write(buff, binary.LittleEndian, int32(10+len(packet.Body)))
+	binary.Write(buff, binary.LittleEndian, packet.ID)
+	binary.Write(buff, binary.LittleEndian, packet.Type)
+	buff.WriteString(packet.Body)
+	buff.Write([]byte{0, 0})
+	return buff.Bytes(), nil
+}
+
+// Read packet from stream
+func ReadPacket(r io.Reader) (*Packet, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	} else if size < 10 || size > MaxPacket {
+		return nil, fmt.Errorf("%w: size %d", ErrPacket, size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return &Packet{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(bytes.TrimRight(data[8:], "\x00")),
+	}, nil
+}
+
+// RCON client, safe to use from many goroutines
+type Client struct {
+	Timeout time.Duration // Time to wait command response, default is [DefaultTimeout]
+
+	conn   net.Conn
+	locker sync.Mutex
+	id     int32
+}
+
+// Connect to RCON server and authenticate
+func Dial(ctx context.Context, address, password string) (*Client, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := NewClient(ctx, conn, password)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// Authenticate in RCON server with conn
+func NewClient(ctx context.Context, conn net.Conn, password string) (*Client, error) {
+	client := &Client{conn: conn}
+	stop := client.deadline(ctx)
+	defer stop()
+
+	id := client.nextID()
+	if err := client.write(Packet{ID: id, Type: TypeAuth, Body: password}); err != nil {
+		return nil, err
+	}
+
+	for {
+		packet, err := ReadPacket(conn)
+		if err != nil {
+			return nil, err
+		} else if packet.Type != TypeAuthResponse {
+			continue // Source servers send empty response before auth response
+		} else if packet.ID == -1 {
+			return nil, ErrAuth
+		} else if packet.ID == id {
+			return client, nil
+		}
+	}
+}
+
+// Close connection
+func (client *Client) Close() error { return client.conn.Close() }
+
+// Send command and return response, multi-packet responses are joined.
+// Errors after command is writed can be after server run command, only [ErrSend]
+// and [ErrCommand] are safe to send command again
+func (client *Client) Command(ctx context.Context, command string) (string, error) {
+	if len(command) > MaxCommand {
+		return "", ErrCommand
+	}
+
+	client.locker.Lock()
+	defer client.locker.Unlock()
+	stop := client.deadline(ctx)
+	defer stop()
+
+	id := client.nextID()
+	if err := client.write(Packet{ID: id, Type: TypeCommand, Body: command}); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSend, err)
+	}
+
+	var response strings.Builder
+	endID, split := int32(0), false
+	for {
+		packet, err := ReadPacket(client.conn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return response.String(), ctx.Err()
+			}
+			return response.String(), err
+		}
+
+		switch packet.ID {
+		case id:
+			response.WriteString(packet.Body)
+			if !split && len(packet.Body) < MaxResponse {
+				return response.String(), nil
+			} else if !split {
+				// Response split in many packets, send empty packet after first
+				// chunk, server reply with same ID after last chunk
+				split, endID = true, client.nextID()
+				if err := client.write(Packet{ID: endID, Type: TypeResponse}); err != nil {
+					return response.String(), err
+				}
+			}
+		case endID:
+			if split {
+				return response.String(), nil
+			}
+		}
+	}
+}
+
+func (client *Client) nextID() int32 {
+	client.id++
+	if client.id <= 0 {
+		client.id = 1
+	}
+	return client.id
+}
+
+func (client *Client) write(packet Packet) error {
+	data, _ := packet.MarshalBinary()
+	_, err := client.conn.Write(data)
+	return err
+}
+
+// Set connection deadline from ctx or Timeout and unblock connection when ctx is done
+func (client *Client) deadline(ctx context.Context) (stop func()) {
+	timeout := client.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	client.conn.SetDeadline(deadline)
+
+	stopCtx := context.AfterFunc(ctx, func() { client.conn.SetDeadline(time.Now()) })
+	return func() {
+		stopCtx()
+		client.conn.SetDeadline(time.Time{})
+	}
+}
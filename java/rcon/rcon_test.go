@@ -0,0 +1,94 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// Fake Minecraft RCON server, read one packet per time and reply like vanilla server
+func fakeServer(t *testing.T, password string, commands map[string]string) string {
+	listen, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listen.Close() })
+
+	go func() {
+		for {
+			conn, err := listen.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				write := func(packet Packet) {
+					data, _ := packet.MarshalBinary()
+					conn.Write(data)
+				}
+
+				for {
+					packet, err := ReadPacket(conn)
+					if err != nil {
+						return
+					}
+
+					switch packet.Type {
+					case TypeAuth:
+						if packet.Body != password {
+							write(Packet{ID: -1, Type: TypeAuthResponse})
+							return
+						}
+						write(Packet{ID: packet.ID, Type: TypeAuthResponse})
+					case TypeCommand:
+						response := commands[packet.Body]
+						for len(response) > MaxResponse {
+							write(Packet{ID: packet.ID, Type: TypeResponse, Body: response[:MaxResponse]})
+							response = response[MaxResponse:]
+						}
+						write(Packet{ID: packet.ID, Type: TypeResponse, Body: response})
+					default:
+						write(Packet{ID: packet.ID, Type: TypeResponse, Body: "Unknown request 0"})
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listen.Addr().String()
+}
+
+func TestClient(t *testing.T) {
+	longResponse := strings.Repeat("go-bds ", 2000)
+	address := fakeServer(t, "secret", map[string]string{
+		"list": "There are 0 of a max of 20 players online: ",
+		"long": longResponse,
+	})
+
+	if _, err := Dial(context.Background(), address, "wrong"); !errors.Is(err, ErrAuth) {
+		t.Errorf("expected auth error, returned %v", err)
+	}
+
+	client, err := Dial(context.Background(), address, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if response, err := client.Command(context.Background(), "list"); err != nil {
+		t.Error(err)
+	} else if response != "There are 0 of a max of 20 players online: " {
+		t.Errorf("invalid list response: %q", response)
+	}
+
+	if response, err := client.Command(context.Background(), "long"); err != nil {
+		t.Error(err)
+	} else if response != longResponse {
+		t.Errorf("multi-packet response not joined, returned %d bytes", len(response))
+	}
+
+	if _, err := client.Command(context.Background(), strings.Repeat("a", MaxCommand+1)); err != ErrCommand {
+		t.Errorf("expected command too long, returned %v", err)
+	}
+}
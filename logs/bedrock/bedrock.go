@@ -16,8 +16,9 @@ import (
 var (
 	_ = logs.RegisterParse[*BedrockParse]("mojang/bedrock")
 
-	_ logs.Log    = &BedrockParse{}
-	_ logs.Player = &BedrockPlayer{}
+	_ logs.Log         = &BedrockParse{}
+	_ logs.StreamParse = &BedrockParse{}
+	_ logs.Player      = &BedrockPlayer{}
 )
 
 type BedrockPlayer struct {
@@ -54,168 +55,235 @@ func (bedrock BedrockParse) GetPlayer(name string) (player []logs.Player, ok boo
 	return
 }
 
+// Split bedrock log line in prefix and message, return false if line not have bedrock prefix
+//
+//	"[2025-03-02 18:47:08:083 INFO] Server started." => {Time: 2025-03-02 18:47:08.083, Level: "INFO", Message: "Server started."}
+func ParseLine(text string) (*logs.Line, bool) {
+	text = strings.TrimPrefix(text, "NO LOG FILE! - ")
+	if !strings.HasPrefix(text, "[") || !strings.Contains(text, "]") {
+		return nil, false
+	}
+
+	prefixEnd := strings.Index(text, "]")
+	prefix := strings.Fields(text[1:prefixEnd])
+	if len(prefix) < 3 {
+		return nil, false
+	}
+
+	date := strings.Join(prefix[:2], " ")
+	if strings.Count(date, ":") == 3 {
+		lastColonIndex := strings.LastIndex(date, ":")
+		date = date[:lastColonIndex] + "." + date[lastColonIndex+1:]
+	}
+	entryTime, err := time.Parse("2006-01-02 15:04:05.999", date)
+	if err != nil {
+		return nil, false
+	}
+
+	return &logs.Line{
+		Time:    entryTime.UTC(),
+		Level:   prefix[len(prefix)-1],
+		Message: strings.TrimSpace(text[prefixEnd+1:]),
+	}, true
+}
+
 func (bedrock *BedrockParse) ParseTime(current time.Time, log io.Reader) error {
 	return bedrock.Parse(log)
 }
 func (bedrock *BedrockParse) Parse(log io.Reader) error {
+	bedrock.reset()
+	scanner := bufio.NewScanner(log)
+	for scanner.Scan() {
+		if _, err := bedrock.parseLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// Process live log line and return events
+func (bedrock *BedrockParse) Next(line string) []logs.Event {
+	if bedrock.ServerPlaform == nil {
+		bedrock.reset()
+	}
+	events, _ := bedrock.parseLine(line)
+	return events
+}
+
+// Bedrock log not have multi-line blocks
+func (bedrock *BedrockParse) Flush() []logs.Event { return nil }
+
+func (bedrock *BedrockParse) reset() {
 	bedrock.ServerPlaform = &logs.Server{Platform: "mojang/bedrock", Ports: []*logs.Port{}} // Init info
 	bedrock.Errs, bedrock.Warngs = []error{}, []error{}
 	bedrock.Players = map[string][]logs.Player{}
+}
 
-	scanner := bufio.NewScanner(log)
-	for scanner.Scan() {
-		text := scanner.Text()
-		text = strings.TrimPrefix(text, "NO LOG FILE! - ")
-		if strings.HasPrefix(text, "setting up server") || strings.HasPrefix(text, "Quit correctly") || text == "" {
-			continue
-		} else if !(strings.Contains(text, "]")) {
-			return logs.ErrSkipPlatform
-		}
-
-		prefixEnd := strings.Index(text, "]")
-		prefix := strings.TrimSpace(strings.TrimSuffix(text[strings.Index(text, "[")+1:prefixEnd], "INFO"))
-		line := strings.TrimSpace(text[prefixEnd+1:])
-		if line == "" {
-			continue // skip
-		} else if len(prefix) < 19 {
-			return logs.ErrSkipPlatform
-		}
-
-		err := error(nil)
-		if strings.HasSuffix(prefix, "ERROR") || strings.HasSuffix(prefix, "WARN") {
-			bedrock.Errs = append(bedrock.Errs, &logs.ErrorReference{LogLevel: 1, FistLine: line})
-			continue
-		}
-
-		// Date
-		if strings.Count(prefix, ":") == 3 {
-			lastColonIndex := strings.LastIndex(prefix, ":")
-			prefix = prefix[:lastColonIndex] + "." + prefix[lastColonIndex+1:]
-		}
-		EntryTime, err := time.Parse("2006-01-02 15:04:05.999", prefix)
-		if err != nil {
-			if EntryTime, err = time.Parse("2006-01-02 15:04:05", prefix[:19]); err != nil {
-				return err
-			}
+func (bedrock *BedrockParse) parseLine(text string) ([]logs.Event, error) {
+	text = strings.TrimPrefix(text, "NO LOG FILE! - ")
+	if strings.HasPrefix(text, "setting up server") || strings.HasPrefix(text, "Quit correctly") || text == "" {
+		return nil, nil
+	} else if !(strings.Contains(text, "]")) {
+		return nil, logs.ErrSkipPlatform
+	}
+
+	prefixEnd := strings.Index(text, "]")
+	prefix := strings.TrimSpace(strings.TrimSuffix(text[strings.Index(text, "[")+1:prefixEnd], "INFO"))
+	line := strings.TrimSpace(text[prefixEnd+1:])
+	if line == "" {
+		return nil, nil // skip
+	} else if len(prefix) < 19 {
+		return nil, logs.ErrSkipPlatform
+	}
+
+	err := error(nil)
+	if strings.HasSuffix(prefix, "ERROR") || strings.HasSuffix(prefix, "WARN") {
+		errorReference := &logs.ErrorReference{LogLevel: 1, FistLine: line}
+		bedrock.Errs = append(bedrock.Errs, errorReference)
+		event := logs.Event{Type: logs.EventWarning, Err: errorReference}
+		if strings.HasSuffix(prefix, "ERROR") {
+			event.Type = logs.EventError
+		}
+		if lineInfo, ok := ParseLine(text); ok {
+			event.Time = lineInfo.Time
 		}
-		EntryTime = EntryTime.UTC() // Convert to UTC time
+		return []logs.Event{event}, nil
+	}
 
-		explodeString := js_types.Slice[string](strings.Fields(line))
-		switch explodeString.At(0) {
-		case "Server":
-			if strings.Contains(text, "started") {
-				bedrock.ServerPlaform.Started = EntryTime
-			}
-		case "Session":
-			if explodeString.At(1) == "ID" {
-				bedrock.SessionID = explodeString.At(2)
-			}
-		case "Branch:":
-			bedrock.Branch = explodeString.At(1)
-		case "Commit":
-			bedrock.CommitID = explodeString.At(2)
-		case "Running":
-			if strings.HasPrefix(explodeString.At(1), "AutoCompaction") {
-				bedrock.LastCompaction = EntryTime
-			}
-		case "Version", "Version:":
-			if len(explodeString) >= 2 {
-				bedrock.ServerPlaform.Version = explodeString.At(-1)
-			}
-		case "IPv6", "IPv4", "Listening":
-			if explodeString.At(-2) == "port:" {
-				protoLocation := 0
-				if explodeString.At(0) == "Listening" {
-					protoLocation = len(explodeString) - 3
-				}
-
-				port, err := strconv.ParseInt(explodeString.At(-1), 10, 16)
-				if err != nil {
-					return err
-				}
-
-				addr := netip.AddrPortFrom(netip.IPv4Unspecified(), uint16(port))
-				if explodeString[protoLocation] == "IPv6" {
-					addr = netip.AddrPortFrom(netip.IPv6Unspecified(), uint16(port))
-				}
-
-				bedrock.ServerPlaform.Ports = append(bedrock.ServerPlaform.Ports, &logs.Port{
-					AddrPort: addr,
-					From:     "server",
-				})
-			}
-		case "Player":
-			// Player connected:
-			// Player disconnected:
-			// Player connected: 2535413418839840
-			// Player disconnected: 2535413418839840
-
-			// Player connected: Sirherobrine, xuid: 2535413418839840
-			// Player Spawned: Sirherobrine xuid: 2535413418839840
-			// Player disconnected: Sirherobrine, xuid: 2535413418839840
-
-			// Player Connected: nod dd, xuid: , pfid: c31902da495f4549
-			// Player Spawned: nod dd xuid: , pfid: c31902da495f4549
-			// Player disconnected: nod dd, xuid: , pfid: c31902da495f4549
-			if !slices.Contains([]string{"connected:", "spawned:", "disconnected:"}, strings.ToLower(explodeString.At(1))) {
-				continue
-			}
+	// Date
+	if strings.Count(prefix, ":") == 3 {
+		lastColonIndex := strings.LastIndex(prefix, ":")
+		prefix = prefix[:lastColonIndex] + "." + prefix[lastColonIndex+1:]
+	}
+	EntryTime, err := time.Parse("2006-01-02 15:04:05.999", prefix)
+	if err != nil {
+		if EntryTime, err = time.Parse("2006-01-02 15:04:05", prefix[:19]); err != nil {
+			return nil, err
+		}
+	}
+	EntryTime = EntryTime.UTC() // Convert to UTC time
 
-			var player, xuid, pfid string
-			if player = strings.TrimSpace(line[strings.Index(line, explodeString.At(1))+len(explodeString.At(1)):]); player == "" {
-				// Old versions of Minecraft Bedrock Server (before 1.6.0)
-				// did not return the names of those who were not logged in
-				continue
+	explodeString := js_types.Slice[string](strings.Fields(line))
+	switch explodeString.At(0) {
+	case "Server":
+		if strings.Contains(text, "started") {
+			bedrock.ServerPlaform.Started = EntryTime
+			return []logs.Event{{Type: logs.EventStarted, Time: EntryTime}}, nil
+		}
+	case "Session":
+		if explodeString.At(1) == "ID" {
+			bedrock.SessionID = explodeString.At(2)
+		}
+	case "Branch:":
+		bedrock.Branch = explodeString.At(1)
+	case "Commit":
+		bedrock.CommitID = explodeString.At(2)
+	case "Running":
+		if strings.HasPrefix(explodeString.At(1), "AutoCompaction") {
+			bedrock.LastCompaction = EntryTime
+		}
+	case "Version", "Version:":
+		if len(explodeString) >= 2 {
+			bedrock.ServerPlaform.Version = explodeString.At(-1)
+			return []logs.Event{{Type: logs.EventVersion, Time: EntryTime, Version: bedrock.ServerPlaform.Version}}, nil
+		}
+	case "IPv6", "IPv4", "Listening":
+		// IPv4 supported, port: 19132
+		// IPv4 supported, port: 19132: Used for gameplay and LAN discovery
+		// Listening on IPv4 port: 19132
+		if portIndex := slices.Index(explodeString, "port:"); portIndex != -1 && portIndex+1 < len(explodeString) {
+			protoLocation := 0
+			if explodeString.At(0) == "Listening" {
+				protoLocation = portIndex - 1
 			}
 
-			if strings.Contains(player, ", xuid:") {
-				xuidd := strings.SplitN(player, ", xuid:", 2)
-				player = strings.TrimSpace(xuidd[0])
-				xuid = strings.TrimSpace(xuidd[1])
-				if strings.Contains(xuid, ", pfid:") {
-					pdis := strings.SplitN(xuid, ", pfid:", 2)
-					xuid = strings.TrimSpace(pdis[0])
-					pfid = strings.TrimSpace(pdis[1])
-				}
+			port, err := strconv.ParseInt(strings.TrimSuffix(explodeString[portIndex+1], ":"), 10, 32)
+			if err != nil {
+				return nil, err
 			}
 
-			if strings.Contains(player, "xuid:") {
-				xuid = player[strings.LastIndex(player, "xuid:")+5:]
-				player = player[:strings.LastIndex(player, "xuid:")-1]
-				if player[len(player)-1] == ',' {
-					player = player[:len(player)-1]
-				}
-				if strings.Contains(xuid, ",") {
-					xuid = xuid[:strings.Index(player, ",")]
-				}
-				xuid = strings.TrimSpace(xuid)
-				player = strings.TrimSpace(player)
+			addr := netip.AddrPortFrom(netip.IPv4Unspecified(), uint16(port))
+			if explodeString[protoLocation] == "IPv6" {
+				addr = netip.AddrPortFrom(netip.IPv6Unspecified(), uint16(port))
 			}
 
-			level := logs.Action(0)
-			switch strings.ToLower(explodeString.At(1)) {
-			case "disconnected:":
-				level = logs.Disconnect
-			case "connected:":
-				level = logs.Connect
-			case "spawned:":
-				level = logs.Spawned
+			listened := &logs.Port{AddrPort: addr, From: "server"}
+			bedrock.ServerPlaform.Ports = append(bedrock.ServerPlaform.Ports, listened)
+			return []logs.Event{{Type: logs.EventPort, Time: EntryTime, Port: listened}}, nil
+		}
+	case "Player":
+		// Player connected:
+		// Player disconnected:
+		// Player connected: 2535413418839840
+		// Player disconnected: 2535413418839840
+
+		// Player connected: Sirherobrine, xuid: 2535413418839840
+		// Player Spawned: Sirherobrine xuid: 2535413418839840
+		// Player disconnected: Sirherobrine, xuid: 2535413418839840
+
+		// Player Connected: nod dd, xuid: , pfid: c31902da495f4549
+		// Player Spawned: nod dd xuid: , pfid: c31902da495f4549
+		// Player disconnected: nod dd, xuid: , pfid: c31902da495f4549
+		if !slices.Contains([]string{"connected:", "spawned:", "disconnected:"}, strings.ToLower(explodeString.At(1))) {
+			return nil, nil
+		}
+
+		var player, xuid, pfid string
+		if player = strings.TrimSpace(line[strings.Index(line, explodeString.At(1))+len(explodeString.At(1)):]); player == "" {
+			// Old versions of Minecraft Bedrock Server (before 1.6.0)
+			// did not return the names of those who were not logged in
+			return nil, nil
+		}
+
+		if strings.Contains(player, ", xuid:") {
+			xuidd := strings.SplitN(player, ", xuid:", 2)
+			player = strings.TrimSpace(xuidd[0])
+			xuid = strings.TrimSpace(xuidd[1])
+			if strings.Contains(xuid, ", pfid:") {
+				pdis := strings.SplitN(xuid, ", pfid:", 2)
+				xuid = strings.TrimSpace(pdis[0])
+				pfid = strings.TrimSpace(pdis[1])
 			}
+		}
 
-			if _, ok := bedrock.Players[player]; !ok {
-				bedrock.Players[player] = []logs.Player{}
+		if strings.Contains(player, "xuid:") {
+			xuid = player[strings.LastIndex(player, "xuid:")+5:]
+			player = player[:strings.LastIndex(player, "xuid:")-1]
+			if player[len(player)-1] == ',' {
+				player = player[:len(player)-1]
 			}
+			if strings.Contains(xuid, ",") {
+				xuid = xuid[:strings.Index(player, ",")]
+			}
+			xuid = strings.TrimSpace(xuid)
+			player = strings.TrimSpace(player)
+		}
+
+		level := logs.Action(0)
+		switch strings.ToLower(explodeString.At(1)) {
+		case "disconnected:":
+			level = logs.Disconnect
+		case "connected:":
+			level = logs.Connect
+		case "spawned:":
+			level = logs.Spawned
+		}
 
-			xuidID, _ := strconv.ParseInt(xuid, 10, 64) // Convert xuid string to XUID int
-			bedrock.Players[player] = append(bedrock.Players[player], BedrockPlayer{
-				Username:   player,
-				Actioned:   level,
-				Timed:      EntryTime,
-				PlayerXUID: xuidID,
-				PFID:       pfid,
-			})
+		if _, ok := bedrock.Players[player]; !ok {
+			bedrock.Players[player] = []logs.Player{}
 		}
+
+		xuidID, _ := strconv.ParseInt(xuid, 10, 64) // Convert xuid string to XUID int
+		playerAction := BedrockPlayer{
+			Username:   player,
+			Actioned:   level,
+			Timed:      EntryTime,
+			PlayerXUID: xuidID,
+			PFID:       pfid,
+		}
+		bedrock.Players[player] = append(bedrock.Players[player], playerAction)
+		return []logs.Event{{Type: logs.EventPlayer, Time: EntryTime, Player: playerAction}}, nil
 	}
 
-	return scanner.Err()
+	return nil, nil
 }
@@ -6,6 +6,9 @@ import (
 
 	"encoding/json"
 	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
 )
 
 var (
@@ -42,3 +45,33 @@ func TestDetectAndParseBedrock(t *testing.T) {
 	}
 	testPrintLog(t, "Parsed log bedrock Static 3:\n%s", parsedLog)
 }
+
+func TestStreamBedrock(t *testing.T) {
+	events := map[logs.EventType]int{}
+	for event := range logs.Stream(strings.NewReader(StaticLogFileBedrock3), &BedrockParse{}) {
+		events[event.Type]++
+	}
+
+	if events[logs.EventStarted] != 1 || events[logs.EventVersion] != 1 {
+		t.Errorf("server start not detected: %v", events)
+	} else if events[logs.EventPort] == 0 {
+		t.Errorf("server ports not detected: %v", events)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	line, ok := ParseLine("[2025-03-02 18:47:08:083 INFO] Server started.")
+	if !ok {
+		t.Fatal("cannot parse bedrock line")
+	} else if line.Level != "INFO" || line.Message != "Server started." || line.Time.Nanosecond() != 83*int(time.Millisecond) {
+		t.Errorf("invalid line: %+v", line)
+	}
+
+	if line, ok = ParseLine("NO LOG FILE! - [2025-01-19 23:35:13 ERROR] Network port occupied"); !ok || line.Level != "ERROR" || line.Message != "Network port occupied" {
+		t.Errorf("invalid old line: %+v", line)
+	}
+
+	if _, ok = ParseLine("Sirherobrine"); ok {
+		t.Error("parsed line without prefix")
+	}
+}
@@ -15,9 +15,10 @@ import (
 )
 
 var (
-	_             = logs.RegisterParse[*JavaParse]("mojang/java") // Register platform
-	_ logs.Log    = (*JavaParse)(nil)
-	_ logs.Player = (*JavaPlayer)(nil)
+	_                  = logs.RegisterParse[*JavaParse]("mojang/java") // Register platform
+	_ logs.Log         = (*JavaParse)(nil)
+	_ logs.StreamParse = (*JavaParse)(nil)
+	_ logs.Player      = (*JavaPlayer)(nil)
 
 	DoneMatch *regex.Regexp = regex.MustCompile(`Done \([0-9\.]+s\)! For help, type "help"( or "\?")?`)
 )
@@ -38,6 +39,9 @@ type JavaParse struct {
 	Players       map[string][]logs.Player `json:"players"`
 	Errs          []error                  `json:"errors"`
 	Warngs        []error                  `json:"warnings"`
+
+	day   time.Time   // Log day
+	block *logs.Event // Current warning or error block
 }
 
 func (java JavaParse) Server() *logs.Server { return java.ServerPlaform }
@@ -48,113 +52,213 @@ func (java JavaParse) GetPlayer(name string) (player []logs.Player, ok bool) {
 	return
 }
 
+// Split java log line in prefix and message, return false if line not have log4j prefix.
+// Line time is set to current day
+//
+//	"[21:41:40] [Server thread/INFO]: Done (38.008s)!" => {Level: "INFO", Thread: "Server thread", Message: "Done (38.008s)!"}
+func ParseLine(text string) (*logs.Line, bool) {
+	if !strings.HasPrefix(text, "[") {
+		return nil, false
+	}
+
+	timeEnd := strings.Index(text, "]")
+	threadStart := strings.Index(text[max(timeEnd, 0):], "[")
+	if timeEnd == -1 || threadStart == -1 {
+		return nil, false
+	}
+	threadStart += timeEnd
+	threadEnd := strings.Index(text[threadStart:], "]")
+	if threadEnd == -1 {
+		return nil, false
+	}
+	threadEnd += threadStart
+
+	moment, err := time.ParseInLocation("15:04:05.999", text[1:timeEnd], time.Local)
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	line := &logs.Line{
+		Time:    time.Date(now.Year(), now.Month(), now.Day(), moment.Hour(), moment.Minute(), moment.Second(), moment.Nanosecond(), time.Local),
+		Level:   text[threadStart+1 : threadEnd],
+		Message: strings.TrimSpace(strings.TrimPrefix(text[threadEnd+1:], ":")),
+	}
+	if thread, level, ok := strings.Cut(line.Level, "/"); ok {
+		line.Thread, line.Level = thread, level
+	}
+	return line, true
+}
+
 func (java *JavaParse) Parse(log io.Reader) error { return java.ParseTime(time.Now(), log) }
 func (java *JavaParse) ParseTime(currentTime time.Time, log io.Reader) error {
+	java.reset(currentTime)
+	valid, scanner := false, bufio.NewScanner(log)
+	for scanner.Scan() {
+		valid = true
+		if _, err := java.parseLine(scanner.Text()); err != nil {
+			if err == logs.ErrSkipPlatform {
+				valid = false
+				break
+			}
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	} else if valid { // return nil if platform is valid
+		return nil
+	}
+	return logs.ErrSkipPlatform
+}
+
+// Process live log line and return events, warning and error blocks are
+// returned when next log line start
+func (java *JavaParse) Next(line string) []logs.Event {
+	if java.ServerPlaform == nil {
+		now := time.Now()
+		java.reset(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	}
+	events, _ := java.parseLine(line)
+	return events
+}
+
+// Return open warning or error block
+func (java *JavaParse) Flush() []logs.Event { return java.closeBlock() }
+
+func (java *JavaParse) reset(currentTime time.Time) {
 	java.ServerPlaform = &logs.Server{Platform: "mojang/java", Ports: []*logs.Port{}} // Init info
 	java.Errs, java.Warngs = []error{}, []error{}
 	java.Players = map[string][]logs.Player{}
+	java.day, java.block = currentTime, nil
+}
 
-	valid, errorReference, scanner := false, error(nil), bufio.NewScanner(log)
-	for scanner.Scan() {
-		line := scanner.Text()
-		valid = true
-		if !(line[0] == 'U' || line[0] == 'S' || line[0] == '[') && errorReference != nil {
-			errorReference.(*logs.ErrorReference).Line = append(errorReference.(*logs.ErrorReference).Line, line)
-			continue
-		} else if !(line[0] == 'U' || line[0] == 'S' || line[0] == '[') {
-			valid = false
-			break
-		}
+// Close current warning/error block and return event
+func (java *JavaParse) closeBlock() []logs.Event {
+	if java.block == nil {
+		return nil
+	}
+	event := *java.block
+	java.block = nil
+	return []logs.Event{event}
+}
 
-		if line[0] == 'U' || line[0] == 'S' {
-			continue // Ignore line
-		}
+func (java *JavaParse) parseLine(line string) ([]logs.Event, error) {
+	if line == "" {
+		return nil, nil
+	} else if !(line[0] == 'U' || line[0] == 'S' || line[0] == '[') && java.block != nil {
+		java.block.Err.Line = append(java.block.Err.Line, line)
+		return nil, nil
+	} else if !(line[0] == 'U' || line[0] == 'S' || line[0] == '[') {
+		return nil, logs.ErrSkipPlatform
+	}
 
-		prefixSplited := [3]string(strings.SplitAfterN(line, "]", 3))
-		prefixSplited[0] = strings.Replace(strings.Replace(strings.TrimSpace(prefixSplited[0][1:]), "[", "", 1), "]", "", 1)
-		prefixSplited[1] = strings.Replace(strings.Replace(strings.TrimSpace(prefixSplited[1][1:]), "[", "", 1), "]", "", 1)
-		prefixSplited[2] = strings.TrimSpace(prefixSplited[2][1:])
+	if line[0] == 'U' || line[0] == 'S' {
+		return nil, nil // Ignore line
+	}
 
-		// Error log
-		if strings.HasSuffix(prefixSplited[1], "WARN") || strings.HasSuffix(prefixSplited[1], "ERROR") {
-			errorReference = &logs.ErrorReference{
-				LogLevel: 1,
-				FistLine: prefixSplited[2],
-			}
-			java.Warngs = append(java.Warngs, errorReference)
-			continue
-		} else if prefixSplited[0][0:4] == "Log4" { // log4j ignore
-			continue
-		}
+	prefixSplited := strings.SplitAfterN(line, "]", 3)
+	if len(prefixSplited) != 3 {
+		return nil, logs.ErrSkipPlatform
+	}
+	prefixSplited[0] = strings.Replace(strings.Replace(strings.TrimSpace(prefixSplited[0][1:]), "[", "", 1), "]", "", 1)
+	prefixSplited[1] = strings.Replace(strings.Replace(strings.TrimSpace(prefixSplited[1][1:]), "[", "", 1), "]", "", 1)
+	prefixSplited[2] = strings.TrimSpace(strings.TrimPrefix(prefixSplited[2], ":"))
 
-		errorReference = nil
-		timeMoment, err := time.ParseInLocation(time.TimeOnly, prefixSplited[0], time.Local)
-		if err != nil {
-			return err
+	// Error log
+	if strings.HasSuffix(prefixSplited[1], "WARN") || strings.HasSuffix(prefixSplited[1], "ERROR") {
+		events := java.closeBlock()
+		errorReference := &logs.ErrorReference{
+			LogLevel: 1,
+			FistLine: prefixSplited[2],
 		}
-		currentTime = currentTime.Add(time.Hour*time.Duration(timeMoment.Hour()) + time.Minute*time.Duration(timeMoment.Minute()) + time.Second*time.Duration(timeMoment.Second()))
+		java.Warngs = append(java.Warngs, errorReference)
 
-		if DoneMatch.Match([]byte(prefixSplited[2])) {
-			java.ServerPlaform.Started = currentTime
-			continue
+		java.block = &logs.Event{Type: logs.EventWarning, Err: errorReference}
+		if strings.HasSuffix(prefixSplited[1], "ERROR") {
+			java.block.Type = logs.EventError
+		}
+		if timeMoment, err := time.ParseInLocation(time.TimeOnly, prefixSplited[0], time.Local); err == nil {
+			java.block.Time = java.lineTime(timeMoment)
 		}
+		return events, nil
+	} else if len(prefixSplited[0]) >= 4 && prefixSplited[0][0:4] == "Log4" { // log4j ignore
+		return nil, nil
+	}
 
-		contentExplode := js_types.Slice[string](strings.Fields(prefixSplited[2]))
-		switch contentExplode.At(0) {
-		case "RCON":
-			if contentExplode.At(-2) == "on" {
-				addr, err := netip.ParseAddrPort(contentExplode.At(-1))
-				if err != nil {
-					return err
-				}
-				java.ServerPlaform.Ports = append(java.ServerPlaform.Ports, &logs.Port{AddrPort: addr, From: "RCON"})
+	events := java.closeBlock()
+	timeMoment, err := time.ParseInLocation(time.TimeOnly, prefixSplited[0], time.Local)
+	if err != nil {
+		return events, err
+	}
+	currentTime := java.lineTime(timeMoment)
+
+	if DoneMatch.Match([]byte(prefixSplited[2])) {
+		java.ServerPlaform.Started = currentTime
+		return append(events, logs.Event{Type: logs.EventStarted, Time: currentTime}), nil
+	}
+
+	contentExplode := js_types.Slice[string](strings.Fields(prefixSplited[2]))
+	switch contentExplode.At(0) {
+	case "RCON":
+		if contentExplode.At(-2) == "on" {
+			addr, err := netip.ParseAddrPort(contentExplode.At(-1))
+			if err != nil {
+				return events, err
 			}
-		case "Starting":
-			switch contentExplode.At(-2) {
-			case "version":
-				version := contentExplode.At(-1)
-				java.ServerPlaform.Version = version
-			case "on":
-				Value := contentExplode.At(-1)
-				if Value[0] == '*' {
-					Value = Value[2:]
-				}
-				port, _ := strconv.ParseInt(Value, 10, 16)
-				java.ServerPlaform.Ports = append(java.ServerPlaform.Ports, &logs.Port{AddrPort: netip.AddrPortFrom(netip.IPv4Unspecified(), uint16(port)), From: "TCP"})
+			listened := &logs.Port{AddrPort: addr, From: "RCON"}
+			java.ServerPlaform.Ports = append(java.ServerPlaform.Ports, listened)
+			events = append(events, logs.Event{Type: logs.EventPort, Time: currentTime, Port: listened})
+		}
+	case "Starting":
+		switch contentExplode.At(-2) {
+		case "version":
+			version := contentExplode.At(-1)
+			java.ServerPlaform.Version = version
+			events = append(events, logs.Event{Type: logs.EventVersion, Time: currentTime, Version: version})
+		case "on":
+			Value := contentExplode.At(-1)
+			if Value[0] == '*' {
+				Value = Value[2:]
 			}
-		default:
-			switch contentExplode.At(-1) {
-			case "game":
-				at3 := strings.ToLower(contentExplode.At(-3))
-				if slices.Contains([]string{"left", "joined"}, at3) {
-					playerName := prefixSplited[2][:strings.LastIndex(prefixSplited[2], contentExplode.At(-3))-1]
-					if _, ok := java.Players[playerName]; !ok {
-						java.Players[playerName] = []logs.Player{}
-					}
-
-					action := logs.Action(0)
-					switch at3 {
-					case "joined":
-						action = logs.Connect
-					case "left":
-						action = logs.Disconnect
-					}
-
-					// Append to struct
-					java.Players[playerName] = append(java.Players[playerName], JavaPlayer{
-						Username: playerName,
-						Actioned: action,
-						Timed:    currentTime,
-					})
+			port, _ := strconv.ParseInt(Value, 10, 16)
+			listened := &logs.Port{AddrPort: netip.AddrPortFrom(netip.IPv4Unspecified(), uint16(port)), From: "TCP"}
+			java.ServerPlaform.Ports = append(java.ServerPlaform.Ports, listened)
+			events = append(events, logs.Event{Type: logs.EventPort, Time: currentTime, Port: listened})
+		}
+	default:
+		switch contentExplode.At(-1) {
+		case "game":
+			at3 := strings.ToLower(contentExplode.At(-3))
+			if slices.Contains([]string{"left", "joined"}, at3) {
+				playerName := prefixSplited[2][:strings.LastIndex(prefixSplited[2], contentExplode.At(-3))-1]
+				if _, ok := java.Players[playerName]; !ok {
+					java.Players[playerName] = []logs.Player{}
+				}
+
+				action := logs.Action(0)
+				switch at3 {
+				case "joined":
+					action = logs.Connect
+				case "left":
+					action = logs.Disconnect
+				}
+
+				// Append to struct
+				player := JavaPlayer{
+					Username: playerName,
+					Actioned: action,
+					Timed:    currentTime,
 				}
+				java.Players[playerName] = append(java.Players[playerName], player)
+				events = append(events, logs.Event{Type: logs.EventPlayer, Time: currentTime, Player: player})
 			}
 		}
 	}
+	return events, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	} else if valid { // return nil if platform is valid
-		return nil
-	}
-	return logs.ErrSkipPlatform
+// Log day with line time
+func (java *JavaParse) lineTime(timeMoment time.Time) time.Time {
+	return java.day.Add(time.Hour*time.Duration(timeMoment.Hour()) + time.Minute*time.Duration(timeMoment.Minute()) + time.Second*time.Duration(timeMoment.Second()))
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
 )
 
 func testPrintLog(t *testing.T, textPrint string, log *JavaParse) {
@@ -27,6 +29,49 @@ func TestDetectAndParseJava(t *testing.T) {
 	testPrintLog(t, "Parsed log java Static 2:\n%s", parsedLog)
 }
 
+func TestStreamJava(t *testing.T) {
+	events := map[logs.EventType]int{}
+	for event := range logs.Stream(strings.NewReader(StaticLogFileJava1), &JavaParse{}) {
+		events[event.Type]++
+	}
+
+	if events[logs.EventStarted] != 1 || events[logs.EventVersion] != 1 {
+		t.Errorf("server start not detected: %v", events)
+	} else if events[logs.EventPort] != 2 {
+		t.Errorf("expected server and RCON port: %v", events)
+	} else if events[logs.EventPlayer] != 2 || events[logs.EventWarning] == 0 {
+		t.Errorf("missing player or warning events: %v", events)
+	}
+
+	// Stack trace lines are appended to block before emit
+	parse := &JavaParse{}
+	if events := parse.Next("[21:41:40] [Server thread/ERROR]: Encountered an unexpected exception"); len(events) != 0 {
+		t.Fatalf("block emitted before end: %v", events)
+	}
+	parse.Next("java.lang.NullPointerException")
+	parse.Next("	at net.minecraft.server.MinecraftServer.run(MinecraftServer.java:1)")
+	if events := parse.Flush(); len(events) != 1 || events[0].Type != logs.EventError || len(events[0].Err.Line) != 2 {
+		t.Errorf("invalid error block: %+v", events)
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	line, ok := ParseLine("[21:41:40] [Server thread/INFO]: Done (38.008s)! For help, type \"help\"")
+	if !ok {
+		t.Fatal("cannot parse java line")
+	} else if line.Level != "INFO" || line.Thread != "Server thread" || line.Message != "Done (38.008s)! For help, type \"help\"" {
+		t.Errorf("invalid line: %+v", line)
+	}
+
+	if line, ok = ParseLine("[22:41:47.744] [Server thread/WARN]: Pocketmine"); !ok || line.Level != "WARN" || line.Message != "Pocketmine" {
+		t.Errorf("invalid pocketmine line: %+v", line)
+	}
+
+	if _, ok = ParseLine("	at java.base/java.lang.Thread.run(Thread.java:1583)"); ok {
+		t.Error("parsed line without prefix")
+	}
+}
+
 var (
 	//go:embed 1.21.4.txt
 	StaticLogFileJava1 string
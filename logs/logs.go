@@ -116,6 +116,14 @@ type Server struct {
 	Ports    []*Port   `json:"ports"`    // Server ports listened
 }
 
+// Log line splited in prefix and message
+type Line struct {
+	Time    time.Time `json:"time"`             // Line time
+	Level   string    `json:"level"`            // Log level, example: INFO, WARN, ERROR
+	Thread  string    `json:"thread,omitempty"` // Thread name if avaible
+	Message string    `json:"message"`          // Line without prefix
+}
+
 type Player interface {
 	Name() string    // Player username
 	Action() Action  // Player action
@@ -0,0 +1,140 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+)
+
+type EventType int // Stream event type
+
+const (
+	_            EventType = iota
+	EventStarted           // Server started and avaible to connect
+	EventPort              // Server listening port
+	EventVersion           // Server version detected
+	EventPlayer            // Player connected, spawned or disconnected
+	EventWarning           // Warning block ended
+	EventError             // Error block ended
+)
+
+func (event EventType) String() string {
+	switch event {
+	case EventStarted:
+		return "started"
+	case EventPort:
+		return "port"
+	case EventVersion:
+		return "version"
+	case EventPlayer:
+		return "player"
+	case EventWarning:
+		return "warning"
+	case EventError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+func (event EventType) MarshalText() ([]byte, error) {
+	return []byte(event.String()), nil
+}
+
+func (event *EventType) UnmarshalText(data []byte) error {
+	switch string(data) {
+	case "started":
+		*event = EventStarted
+	case "port":
+		*event = EventPort
+	case "version":
+		*event = EventVersion
+	case "player":
+		*event = EventPlayer
+	case "warning":
+		*event = EventWarning
+	case "error":
+		*event = EventError
+	default:
+		return fmt.Errorf("unknown event: %s", data)
+	}
+	return nil
+}
+
+// Event emitted by live log parse
+type Event struct {
+	Type    EventType       `json:"type"`              // Event type
+	Time    time.Time       `json:"time"`              // Log line time
+	Version string          `json:"version,omitempty"` // Server version to [EventVersion]
+	Port    *Port           `json:"port,omitempty"`    // Listened port to [EventPort]
+	Player  Player          `json:"player,omitempty"`  // Player action to [EventPlayer]
+	Err     *ErrorReference `json:"error,omitempty"`   // Log block to [EventWarning] and [EventError]
+}
+
+// Parse log line by line and return events as soon as avaible
+type StreamParse interface {
+	Next(line string) []Event // Process next log line
+	Flush() []Event           // Return pending events on end of log, example open error blocks
+}
+
+// Max log line size read by [Stream], commands like "save query" print long lines
+var MaxLineSize = 16 << 20
+
+// Process log lines from reader as arrive and yield events,
+// read error or line bigger than [MaxLineSize] is yielded as last [EventError],
+// closed pipe or file is not error
+func Stream(log io.Reader, parse StreamParse) iter.Seq[Event] {
+	return func(yield func(Event) bool) {
+		scanner := bufio.NewScanner(log)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), MaxLineSize)
+		for scanner.Scan() {
+			for _, event := range parse.Next(scanner.Text()) {
+				if !yield(event) {
+					return
+				}
+			}
+		}
+		for _, event := range parse.Flush() {
+			if !yield(event) {
+				return
+			}
+		}
+		// Closed stdout is normal end, example [StreamProc] ctx done
+		if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrClosedPipe) && !errors.Is(err, os.ErrClosed) {
+			yield(Event{Type: EventError, Time: time.Now(), Err: &ErrorReference{FistLine: fmt.Sprintf("cannot read log: %s", err)}})
+		}
+	}
+}
+
+// Attach parse to process stdout and send events to channel,
+// channel is closed when stdout end or ctx is done
+func StreamProc(ctx context.Context, proc exec.Proc, parse StreamParse) (<-chan Event, error) {
+	stdout, err := proc.StdoutFork()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer stdout.Close()
+		stop := context.AfterFunc(ctx, func() { stdout.Close() })
+		defer stop()
+
+		for event := range Stream(stdout, parse) {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
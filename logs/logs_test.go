@@ -1,12 +1,18 @@
 package logs_test
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
 	"sirherobrine23.com.br/go-bds/go-bds/logs"
 	_ "sirherobrine23.com.br/go-bds/go-bds/logs/bedrock"
 	_ "sirherobrine23.com.br/go-bds/go-bds/logs/java"
@@ -33,3 +39,63 @@ func TestLogs(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// Parse without events, only count lines
+type countParse struct{ lines []int }
+
+func (parse *countParse) Next(line string) []logs.Event {
+	parse.lines = append(parse.lines, len(line))
+	return nil
+}
+func (parse *countParse) Flush() []logs.Event { return nil }
+
+func TestStream(t *testing.T) {
+	// Line bigger than default bufio.Scanner limit
+	long := strings.Repeat("a", 100<<10)
+	parse := &countParse{}
+	for event := range logs.Stream(strings.NewReader(long+"\nend\n"), parse) {
+		t.Errorf("unexpected event: %v", event.Err)
+	}
+	if len(parse.lines) != 2 || parse.lines[0] != len(long) {
+		t.Errorf("long line not read: %v", parse.lines)
+	}
+
+	// Read error is last event
+	var last *logs.Event
+	for event := range logs.Stream(io.MultiReader(strings.NewReader("line\n"), iotest.ErrReader(errors.New("broken pipe"))), &countParse{}) {
+		last = &event
+	}
+	if last == nil || last.Type != logs.EventError || !strings.Contains(last.Err.Error(), "broken pipe") {
+		t.Errorf("read error not reported: %+v", last)
+	}
+}
+
+// Process with only stdout, stdout is open until closed by reader
+type stdoutProc struct{ exec.Proc }
+
+func (stdoutProc) StdoutFork() (io.ReadCloser, error) {
+	r, _ := io.Pipe()
+	return r, nil
+}
+
+func TestStreamProcCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := logs.StreamProc(ctx, stdoutProc{}, &countParse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			t.Errorf("unexpected event on cancel: %+v", event.Err)
+		case <-timeout:
+			t.Fatal("events not closed on cancel")
+		}
+	}
+}
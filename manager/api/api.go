@@ -0,0 +1,287 @@
+// HTTP/JSON API to control servers of [manager.Manager], console is streamed with
+// Server-Sent Events. All routes require "Authorization: Bearer <token>"
+//
+//	GET  /instances                   list instances allowed to token
+//	GET  /instances/{name}            instance config and status
+//	POST /instances/{name}/start      start server
+//	POST /instances/{name}/stop       stop server
+//	POST /instances/{name}/restart    stop and start server
+//	POST /instances/{name}/command    run console command, body {"command": "list"}
+//	GET  /instances/{name}/console    console lines as Server-Sent Events
+//	GET  /instances/{name}/backup     download backup, ?format=tar or zip
+//	POST /instances/{name}/restore    restore backup from body, ?format=tar or zip
+//	POST /instances/{name}/install    install version, body {"version": "1.21.4"}, empty to current
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/manager"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+// Token allowed to access API
+type Token struct {
+	Token     string   `json:"token"`
+	Instances []string `json:"instances"` // Instances allowed to token, "*" allow all
+}
+
+// Token can access instance
+func (token Token) Allowed(name string) bool {
+	return slices.Contains(token.Instances, "*") || slices.Contains(token.Instances, name)
+}
+
+// Instance with status
+type Status struct {
+	manager.Instance
+	Running bool `json:"running"`
+}
+
+type tokenKey struct{}
+
+func writeJson(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	jsenc := json.NewEncoder(w)
+	jsenc.SetIndent("", "  ")
+	jsenc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJson(w, code, map[string]any{
+		"success": false,
+		"error":   err.Error(),
+	})
+}
+
+// HTTP code to manager errors
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, manager.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, exec.ErrRunning), errors.Is(err, exec.ErrNoRunning), errors.Is(err, server.ErrRunning), errors.Is(err, manager.ErrPortInUse):
+		return http.StatusConflict
+	case errors.Is(err, manager.ErrConfig), errors.Is(err, manager.ErrName), errors.Is(err, manager.ErrPlatform), errors.Is(err, manager.ErrBackend),
+		errors.Is(err, server.ErrFormat), errors.Is(err, server.ErrArchivePath), errors.Is(err, server.ErrArchiveLayout):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// Create handler to manager, can be mounted in other mux with [http.StripPrefix]
+func NewHandler(manage *manager.Manager, tokens []Token) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /instances", func(w http.ResponseWriter, r *http.Request) {
+		token := r.Context().Value(tokenKey{}).(Token)
+		instances := []Status{}
+		for _, instance := range manage.List() {
+			if token.Allowed(instance.Name) {
+				instances = append(instances, Status{instance, manage.Running(instance.Name)})
+			}
+		}
+		writeJson(w, 200, instances)
+	})
+
+	mux.HandleFunc("GET /instances/{name}", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		current, err := manage.Get(name)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, Status{current, manage.Running(name)})
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/start", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		if err := manage.Start(r.Context(), name); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true})
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/stop", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		status, err := manage.Stop(r.Context(), name)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true, "status": status})
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/restart", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		if err := manage.Restart(r.Context(), name); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true})
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/command", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		var body struct {
+			Command string `json:"command"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Command) == "" {
+			writeError(w, 400, errors.New("required json body with command"))
+			return
+		}
+		srv, err := runningServer(manage, name)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		lines, err := srv.RunCommand(r.Context(), body.Command)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true, "lines": lines})
+	}))
+
+	mux.HandleFunc("GET /instances/{name}/console", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		srv, err := runningServer(manage, name)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		stdout, err := srv.Proc().StdoutFork()
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		defer stdout.Close()
+		stop := context.AfterFunc(r.Context(), func() { stdout.Close() })
+		defer stop()
+
+		flusher, _ := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(200)
+		if flusher != nil {
+			flusher.Flush()
+		}
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", strings.TrimSuffix(scanner.Text(), "\r")); err != nil {
+				return
+			} else if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		fmt.Fprint(w, "event: end\ndata: \n\n")
+	}))
+
+	mux.HandleFunc("GET /instances/{name}/backup", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		format := server.ArchiveFormat(r.URL.Query().Get("format"))
+		if format == "" {
+			format = server.FormatTar
+		} else if format != server.FormatTar && format != server.FormatZip {
+			writeError(w, 400, fmt.Errorf("%w: %q", server.ErrFormat, format))
+			return
+		}
+		if _, err := manage.Get(name); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/"+string(format))
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+		w.Header().Set("Trailer", "X-Backup-Error") // Status is sent before archive
+		w.WriteHeader(200)
+		if err := manage.Backup(r.Context(), name, w, format); err != nil {
+			w.Header().Set("X-Backup-Error", err.Error())
+		}
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/restore", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		format := server.ArchiveFormat(r.URL.Query().Get("format"))
+		if format == "" {
+			format = server.FormatTar
+		}
+		if err := manage.Restore(name, r.Body, format); err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true})
+	}))
+
+	mux.HandleFunc("POST /instances/{name}/install", instance(func(w http.ResponseWriter, r *http.Request, name string) {
+		var body struct {
+			Version string `json:"version"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeError(w, 400, err)
+				return
+			}
+		}
+		if body.Version != "" {
+			if err := manage.Update(name, func(instance *manager.Instance) error { instance.Version = body.Version; return nil }); err != nil {
+				writeError(w, errorCode(err), err)
+				return
+			}
+		}
+		srv, err := manage.Install(name)
+		if err != nil {
+			writeError(w, errorCode(err), err)
+			return
+		}
+		writeJson(w, 200, map[string]any{"success": true, "version": srv.ServerVersion()})
+	}))
+
+	return auth(tokens, mux)
+}
+
+// Check token and save in request context
+func auth(tokens []Token, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if ok {
+			for _, token := range tokens {
+				if token.Token != "" && subtle.ConstantTimeCompare([]byte(token.Token), []byte(value)) == 1 {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, token)))
+					return
+				}
+			}
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+	})
+}
+
+// Check token can access instance in path
+func instance(handler func(w http.ResponseWriter, r *http.Request, name string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if token := r.Context().Value(tokenKey{}).(Token); !token.Allowed(name) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("token not allowed to %q", name))
+			return
+		}
+		handler(w, r, name)
+	}
+}
+
+// Server with console attached
+func runningServer(manage *manager.Manager, name string) (server.Server, error) {
+	if !manage.Running(name) {
+		return nil, exec.ErrNoRunning
+	}
+	srv, err := manage.Server(name)
+	if err != nil {
+		return nil, err
+	} else if !server.Running(srv.Proc()) {
+		return nil, fmt.Errorf("%w: console not attached, server started by other process", exec.ErrNoRunning)
+	}
+	return srv, nil
+}
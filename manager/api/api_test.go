@@ -0,0 +1,91 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sirherobrine23.com.br/go-bds/go-bds/manager"
+)
+
+func TestHandler(t *testing.T) {
+	root := t.TempDir()
+	manage, err := manager.Open(filepath.Join(root, "instances.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manage.Ports.Probe = func(string, uint16) bool { return true }
+	for _, name := range []string{"lobby", "survival"} {
+		err = manage.Create(manager.Instance{
+			Name:          name,
+			Platform:      manager.Paper,
+			VersionFolder: filepath.Join(root, "versions"),
+			JavaFolder:    filepath.Join(root, "java"),
+			Cwd:           filepath.Join(root, name),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", http.StripPrefix("/api", NewHandler(manage, []Token{
+		{Token: "admin", Instances: []string{"*"}},
+		{Token: "lobby", Instances: []string{"lobby"}},
+	})))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	request := func(method, path, token, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+"/api"+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { res.Body.Close() })
+		return res
+	}
+
+	if res := request("GET", "/instances", "", ""); res.StatusCode != 401 {
+		t.Errorf("expected 401 without token, got %d", res.StatusCode)
+	} else if res = request("GET", "/instances", "invalid", ""); res.StatusCode != 401 {
+		t.Errorf("expected 401 to invalid token, got %d", res.StatusCode)
+	}
+
+	var list []Status
+	if res := request("GET", "/instances", "lobby", ""); res.StatusCode != 200 {
+		t.Fatalf("list status %d", res.StatusCode)
+	} else if err = json.NewDecoder(res.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	} else if len(list) != 1 || list[0].Name != "lobby" || list[0].Running {
+		t.Errorf("token scope not applied to list: %+v", list)
+	}
+
+	tests := []struct {
+		method, path, token, body string
+		code                      int
+	}{
+		{"GET", "/instances/survival", "lobby", "", 403},
+		{"GET", "/instances/survival", "admin", "", 200},
+		{"GET", "/instances/missing", "admin", "", 404},
+		{"POST", "/instances/lobby/stop", "lobby", "", 409},
+		{"POST", "/instances/lobby/command", "lobby", `{"command": "list"}`, 409},
+		{"POST", "/instances/lobby/command", "lobby", `{}`, 400},
+		{"GET", "/instances/lobby/console", "lobby", "", 409},
+		{"GET", "/instances/lobby/backup?format=rar", "lobby", "", 400},
+	}
+	for _, test := range tests {
+		if res := request(test.method, test.path, test.token, test.body); res.StatusCode != test.code {
+			t.Errorf("%s %s: expected %d, got %d", test.method, test.path, test.code, res.StatusCode)
+		}
+	}
+}
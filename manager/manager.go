@@ -0,0 +1,350 @@
+// Manage many server instances with registry saved to JSON file,
+// instances running after program restart are found again
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+var (
+	ErrExists   error = errors.New("instance already exists")       // Create with name in use
+	ErrNotFound error = errors.New("instance not found")            // Name not in registry
+	ErrName     error = errors.New("invalid instance name")         // Name with chars not allowed in folders and containers
+	ErrPlatform error = errors.New("platform not supported")        // Unknown instance platform
+	ErrBackend  error = errors.New("process backend not supported") // Unknown instance backend
+	ErrConfig   error = errors.New("invalid instance config")       // Required instance fields not set
+
+	nameMatch = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+)
+
+// Server platform
+type Platform string
+
+const (
+	Bedrock    Platform = "bedrock"  // Minecraft Bedrock Dedicated server
+	Pocketmine Platform = "pmmp"     // PocketMine-MP
+	AllayMC    Platform = "allaymc"  // AllayMC
+	Java       Platform = "java"     // Mojang Java server
+	Paper      Platform = "paper"    // PaperMC
+	Folia      Platform = "folia"    // PaperMC Folia
+	Velocity   Platform = "velocity" // PaperMC Velocity proxy
+	Purpur     Platform = "purpur"   // Purpur
+	Spigot     Platform = "spigot"   // SpigotMC, build with BuildTools
+)
+
+// Platforms supported
+var Platforms = []Platform{Bedrock, Pocketmine, AllayMC, Java, Paper, Folia, Velocity, Purpur, Spigot}
+
+// Platform run with java server struct
+func (platform Platform) IsJava() bool {
+	return platform == Java || platform == Paper || platform == Folia || platform == Velocity || platform == Purpur || platform == Spigot
+}
+
+// Process backend to run server
+type Backend string
+
+const (
+	BackendOs     Backend = "os"     // Host process, [*exec.Os]
+	BackendProot  Backend = "proot"  // Process inside rootfs, [*exec.Proot]
+	BackendDocker Backend = "docker" // Docker container, [*exec.DockerContainer]
+)
+
+// Network port used by instance
+type Port struct {
+	Name    string `json:"name"`    // Port usage, example "server", "serverv6" or "rcon"
+	Network string `json:"network"` // "tcp" or "udp"
+	Port    uint16 `json:"port"`
+}
+
+// Instance config saved in registry
+type Instance struct {
+	Name          string    `json:"name"`                  // Unique name
+	Platform      Platform  `json:"platform"`              // Server platform
+	Version       string    `json:"version"`               // Server version, "" or [Latest] is replaced by latest release on install
+	VersionFolder string    `json:"version_folder"`        // Folder to storage server versions, can be shared by instances
+	JavaFolder    string    `json:"java_folder,omitempty"` // Folder to storage java installs, required to Java platforms and AllayMC
+	Cwd           string    `json:"cwd"`                   // Server folder
+	Upper         string    `json:"upper,omitempty"`       // Overlayfs upper folder to Bedrock and Pocketmine
+	Workdir       string    `json:"workdir,omitempty"`     // Overlayfs workdir to Bedrock and Pocketmine
+	Ports         []Port    `json:"ports,omitempty"`       // Ports used by server
+	Backend       Backend   `json:"backend"`               // Process backend, default is [BackendOs]
+	Rootfs        string    `json:"rootfs,omitempty"`      // Rootfs to [BackendProot]
+	Image         string    `json:"image,omitempty"`       // Image to [BackendDocker]
+	Env           exec.Env  `json:"env,omitempty"`         // Extra environment to server process
+	PID           int       `json:"pid,omitempty"`         // Process ID of last start, used to find running server after restart
+	Container     string    `json:"container,omitempty"`   // Docker container name
+	Created       time.Time `json:"created"`
+	Updated       time.Time `json:"updated"`
+}
+
+// Check instance fields
+func (instance Instance) Validate() error {
+	if !nameMatch.MatchString(instance.Name) {
+		return fmt.Errorf("%w: %q", ErrName, instance.Name)
+	} else if !slices.Contains(Platforms, instance.Platform) {
+		return fmt.Errorf("%w: %q", ErrPlatform, instance.Platform)
+	} else if !slices.Contains([]Backend{BackendOs, BackendProot, BackendDocker}, instance.Backend) {
+		return fmt.Errorf("%w: %q", ErrBackend, instance.Backend)
+	} else if instance.Cwd == "" || instance.VersionFolder == "" {
+		return fmt.Errorf("%w: cwd and version_folder required", ErrConfig)
+	} else if (instance.Platform.IsJava() || instance.Platform == AllayMC) && instance.JavaFolder == "" {
+		return fmt.Errorf("%w: java_folder required to %s", ErrConfig, instance.Platform)
+	} else if instance.Backend == BackendProot && instance.Rootfs == "" {
+		return fmt.Errorf("%w: rootfs required to proot", ErrConfig)
+	}
+	for _, port := range instance.Ports {
+		if port.Network != "tcp" && port.Network != "udp" {
+			return fmt.Errorf("%w: port %q network %q", ErrConfig, port.Name, port.Network)
+		} else if !instance.HasProperties() && !slices.Contains(DefaultPorts(instance.Platform), port) {
+			return fmt.Errorf("%w: %s port not configurable, only %+v", ErrConfig, instance.Platform, DefaultPorts(instance.Platform))
+		}
+	}
+	return nil
+}
+
+// Registry file content
+type registryFile struct {
+	Instances []*Instance `json:"instances"`
+}
+
+// Instance with server struct in memory
+type entry struct {
+	locker   sync.Mutex
+	instance *Instance
+	server   server.Server // Created on Install or Start
+	proc     exec.Proc     // Process backend, kept to server rebuilds
+	adopted  *os.Process   // Process started by previous manager, without console
+}
+
+// Manage instances saved in Registry file
+type Manager struct {
+	Registry    string             // Registry file path
+	Versions    Versions           // Versions to build servers
+	Ports       *Allocator         // Ports claimed by instances
+	StopOptions server.StopOptions // Options to stop processes found by Reconcile
+
+	locker  sync.Mutex
+	entries map[string]*entry
+}
+
+// Load registry and find running instances, registry is created on first change
+// if not exists. If versions is nil use [*RemoteVersions]
+func Open(registry string, versions Versions) (*Manager, error) {
+	if versions == nil {
+		versions = &RemoteVersions{}
+	}
+	manager := &Manager{Registry: registry, Versions: versions, Ports: &Allocator{}, entries: map[string]*entry{}}
+
+	data, err := os.ReadFile(registry)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if err == nil {
+		var content registryFile
+		if err = json.Unmarshal(data, &content); err != nil {
+			return nil, fmt.Errorf("cannot decode registry: %w", err)
+		}
+		for _, instance := range content.Instances {
+			manager.entries[instance.Name] = &entry{instance: instance}
+			manager.Ports.Claim(instance.Name, instance.Ports) // Conflicts are returned on start
+		}
+	}
+
+	if err = manager.Reconcile(); err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// Save registry with write to temporary file and rename
+func (manager *Manager) save() error {
+	content := registryFile{Instances: []*Instance{}}
+	for _, current := range manager.entries {
+		content.Instances = append(content.Instances, current.instance)
+	}
+	slices.SortFunc(content.Instances, func(a, b *Instance) int { return strings.Compare(a.Name, b.Name) })
+
+	return saveJSON(manager.Registry, content)
+}
+
+// Write JSON to temporary file and rename, file is never partially written
+func saveJSON(file string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	} else if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmpFile := file + ".tmp"
+	if err = os.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, file)
+}
+
+func (manager *Manager) entry(name string) (*entry, error) {
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	current, ok := manager.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return current, nil
+}
+
+// Instances sorted by name
+func (manager *Manager) List() []Instance {
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	instances := []Instance{}
+	for _, current := range manager.entries {
+		instances = append(instances, *current.instance)
+	}
+	slices.SortFunc(instances, func(a, b Instance) int { return strings.Compare(a.Name, b.Name) })
+	return instances
+}
+
+// Get instance config
+func (manager *Manager) Get(name string) (Instance, error) {
+	current, err := manager.entry(name)
+	if err != nil {
+		return Instance{}, err
+	}
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	return *current.instance, nil
+}
+
+// Add instance to registry and create server folders, server is installed on [Manager.Install] or [Manager.Start]
+func (manager *Manager) Create(instance Instance) error {
+	if instance.Backend == "" {
+		instance.Backend = BackendOs
+	}
+	if instance.Backend == BackendDocker && instance.Container == "" {
+		instance.Container = "go-bds-" + instance.Name
+	}
+	if len(instance.Ports) == 0 {
+		instance.Ports = DefaultPorts(instance.Platform)
+	}
+	if err := instance.Validate(); err != nil {
+		return err
+	}
+	instance.PID, instance.Created, instance.Updated = 0, time.Now().UTC(), time.Now().UTC()
+
+	for _, folder := range []string{instance.Cwd, instance.Upper, instance.Workdir, instance.VersionFolder} {
+		if folder == "" {
+			continue
+		} else if err := os.MkdirAll(folder, 0755); err != nil {
+			return err
+		}
+	}
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	if _, exists := manager.entries[instance.Name]; exists {
+		return fmt.Errorf("%w: %q", ErrExists, instance.Name)
+	}
+	allocated, err := manager.Ports.Allocate(instance.Name, instance.Ports)
+	if err != nil {
+		return err
+	} else if err = fixedPorts(instance, allocated); err != nil {
+		manager.Ports.Release(instance.Name)
+		return err
+	}
+	instance.Ports = allocated
+	manager.entries[instance.Name] = &entry{instance: &instance}
+	if err = manager.save(); err != nil {
+		delete(manager.entries, instance.Name)
+		manager.Ports.Release(instance.Name)
+		return err
+	}
+	return nil
+}
+
+// Change instance config, instance must be stopped.
+// Name cannot be changed, server is rebuild on next start
+func (manager *Manager) Update(name string, update func(instance *Instance) error) error {
+	current, err := manager.entry(name)
+	if err != nil {
+		return err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	if current.running() {
+		return server.ErrRunning
+	}
+
+	manager.locker.Lock()
+	instance := *current.instance
+	manager.locker.Unlock()
+	if err = update(&instance); err != nil {
+		return err
+	} else if instance.Name != name {
+		return fmt.Errorf("%w: name cannot be changed", ErrConfig)
+	} else if err = instance.Validate(); err != nil {
+		return err
+	}
+	allocated, err := manager.Ports.Allocate(name, instance.Ports)
+	if err != nil {
+		return err
+	} else if err = fixedPorts(instance, allocated); err != nil {
+		manager.Ports.Claim(name, current.instance.Ports)
+		return err
+	}
+	instance.Ports = allocated
+	instance.Updated = time.Now().UTC()
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	old := current.instance
+	current.instance, current.server, current.proc = &instance, nil, nil
+	if err = manager.save(); err != nil {
+		current.instance = old
+		manager.Ports.Claim(name, old.Ports)
+		return err
+	}
+	return nil
+}
+
+// Remove instance from registry, instance must be stopped.
+// If removeFiles is true delete Cwd, Upper and Workdir folders
+func (manager *Manager) Delete(name string, removeFiles bool) error {
+	current, err := manager.entry(name)
+	if err != nil {
+		return err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	if current.running() {
+		return server.ErrRunning
+	}
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	delete(manager.entries, name)
+	if err = manager.save(); err != nil {
+		manager.entries[name] = current
+		return err
+	}
+	manager.Ports.Release(name)
+
+	if removeFiles {
+		for _, folder := range []string{current.instance.Cwd, current.instance.Upper, current.instance.Workdir} {
+			if folder != "" {
+				err = errors.Join(err, os.RemoveAll(folder))
+			}
+		}
+	}
+	return err
+}
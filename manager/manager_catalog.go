@@ -0,0 +1,114 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var ErrOffline error = errors.New("versions not in catalog and offline mode enabled") // Offline catalog without platform file
+
+// Default time to fetch new versions after last catalog update
+const DefaultCatalogAge = 6 * time.Hour
+
+// Versions saved to disk, one JSON file per platform.
+// Fetchers only append new versions to saved list, so hashes and Java version
+// of old releases are never downloaded again
+type Catalog struct {
+	Folder  string        // Folder to save platforms files
+	Offline bool          // Never fetch upstream, use only saved versions
+	MaxAge  time.Duration // Fetch new versions after this time, default is DefaultCatalogAge
+}
+
+type catalogFile struct {
+	Updated  time.Time       `json:"updated"`
+	Versions json.RawMessage `json:"versions"`
+}
+
+// Platform file path
+func (catalog *Catalog) File(platform Platform) string {
+	return filepath.Join(catalog.Folder, string(platform)+".json")
+}
+
+// Decode platform versions into versions pointer and return last update,
+// return [fs.ErrNotExist] if platform not saved
+func (catalog *Catalog) Load(platform Platform, versions any) (time.Time, error) {
+	data, err := os.ReadFile(catalog.File(platform))
+	if err != nil {
+		return time.Time{}, err
+	}
+	var content catalogFile
+	if err = json.Unmarshal(data, &content); err != nil {
+		return time.Time{}, fmt.Errorf("cannot decode %s catalog: %w", platform, err)
+	} else if err = json.Unmarshal(content.Versions, versions); err != nil {
+		return time.Time{}, fmt.Errorf("cannot decode %s catalog: %w", platform, err)
+	}
+	return content.Updated, nil
+}
+
+// Save platform versions with update time
+func (catalog *Catalog) Save(platform Platform, versions any, updated time.Time) error {
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+	return saveJSON(catalog.File(platform), catalogFile{Updated: updated.UTC(), Versions: data})
+}
+
+// Catalog updated time is older than MaxAge
+func (catalog *Catalog) Expired(updated time.Time) bool {
+	maxAge := catalog.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultCatalogAge
+	}
+	return time.Since(updated) > maxAge
+}
+
+// Load saved versions on first use and fetch new versions when catalog expire,
+// without catalog versions are fetched only once. If fetch fail saved versions are kept
+func (remote *RemoteVersions) sync(platform Platform, versions any, fetch func() error) error {
+	if remote.updated == nil {
+		remote.updated = map[Platform]time.Time{}
+	}
+	updated, loaded := remote.updated[platform]
+	if !loaded && remote.Catalog != nil {
+		var err error
+		if updated, err = remote.Catalog.Load(platform, versions); err == nil {
+			remote.updated[platform] = updated
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	switch {
+	case remote.Catalog == nil && !updated.IsZero():
+		return nil
+	case remote.Catalog != nil && remote.Catalog.Offline:
+		if updated.IsZero() {
+			return fmt.Errorf("%w: %s", ErrOffline, platform)
+		}
+		return nil
+	case remote.Catalog != nil && !updated.IsZero() && !remote.Catalog.Expired(updated):
+		return nil
+	}
+
+	if err := fetch(); err != nil {
+		// Use expired catalog if upstream fail, reload to drop versions appended before error
+		if remote.Catalog == nil || updated.IsZero() {
+			return err
+		} else if _, loadErr := remote.Catalog.Load(platform, versions); loadErr != nil {
+			return errors.Join(err, loadErr)
+		}
+		return nil
+	}
+	updated = time.Now()
+	remote.updated[platform] = updated
+	if remote.Catalog != nil {
+		return remote.Catalog.Save(platform, versions, updated)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/properties"
+	"sirherobrine23.com.br/go-bds/overlayfs"
+)
+
+// Server config is in server.properties, AllayMC and Velocity use other files
+func (instance Instance) HasProperties() bool {
+	return instance.Platform != AllayMC && instance.Platform != Velocity
+}
+
+// server.properties path, Bedrock and Pocketmine with overlayfs use Upper folder
+// because Cwd is mounted only on start
+func (instance Instance) PropertiesFile() string {
+	folder := instance.Cwd
+	if (instance.Platform == Bedrock || instance.Platform == Pocketmine) && instance.Upper != "" && overlayfs.OverlayfsAvaible() {
+		folder = instance.Upper
+	}
+	return filepath.Join(folder, "server.properties")
+}
+
+// server.properties from version folder, used as overlayfs lower layer by Bedrock and Pocketmine
+func (instance Instance) lowerPropertiesFile() string {
+	if instance.PropertiesFile() == filepath.Join(instance.Cwd, "server.properties") {
+		return ""
+	} else if instance.Platform == Pocketmine {
+		return filepath.Join(instance.VersionFolder, "pocketmine", instance.Version, "server.properties")
+	}
+	return filepath.Join(instance.VersionFolder, instance.Version, "server.properties")
+}
+
+// Copy server.properties from lower layer to Upper if not exists, Upper file hide lower file
+// after mount so all keys are required in it
+func (instance Instance) seedProperties() (string, error) {
+	file, lower := instance.PropertiesFile(), instance.lowerPropertiesFile()
+	if lower == "" {
+		return file, nil
+	} else if _, err := os.Stat(file); !os.IsNotExist(err) {
+		return file, err
+	}
+
+	data, err := os.ReadFile(lower)
+	if os.IsNotExist(err) {
+		return file, nil
+	} else if err != nil {
+		return file, err
+	} else if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return file, err
+	}
+	return file, os.WriteFile(file, data, 0644)
+}
+
+// Instance server.properties keys, nested keys are dotted like "query.port"
+func (manager *Manager) Properties(name string) (map[string]string, error) {
+	instance, err := manager.Get(name)
+	if err != nil {
+		return nil, err
+	} else if !instance.HasProperties() {
+		return nil, fmt.Errorf("%w: %s not use server.properties", ErrPlatform, instance.Platform)
+	}
+	keys, err := ReadProperties(instance.PropertiesFile())
+	if lower := instance.lowerPropertiesFile(); os.IsNotExist(err) && lower != "" {
+		return ReadProperties(lower)
+	}
+	return keys, err
+}
+
+// Set keys in instance server.properties, server must be restarted to apply
+func (manager *Manager) SetProperties(name string, values map[string]string) error {
+	instance, err := manager.Get(name)
+	if err != nil {
+		return err
+	} else if !instance.HasProperties() {
+		return fmt.Errorf("%w: %s not use server.properties", ErrPlatform, instance.Platform)
+	}
+	file, err := instance.seedProperties()
+	if err != nil {
+		return err
+	}
+	return SetProperties(file, values)
+}
+
+// Write instance backup, running Java and Bedrock servers are archived with saves paused.
+// Server found running after manager restart cannot be paused and return [server.ErrRunning]
+func (manager *Manager) Backup(ctx context.Context, name string, w io.Writer, format server.ArchiveFormat) (err error) {
+	if format != server.FormatTar && format != server.FormatZip {
+		return fmt.Errorf("%w: %q", server.ErrFormat, format)
+	}
+	current, err := manager.entry(name)
+	if err != nil {
+		return err
+	}
+
+	current.locker.Lock()
+	running := current.running()
+	if running && current.adopted != nil {
+		current.locker.Unlock()
+		return fmt.Errorf("%w: server started before manager, restart server to backup", server.ErrRunning)
+	}
+	srv, err := manager.server(current)
+	current.locker.Unlock()
+	if err != nil {
+		return err
+	}
+
+	switch srv := srv.(type) {
+	case *java.Server:
+		if running && format == server.FormatZip {
+			_, err = srv.HotZip(ctx, w, java.BackupOptions{})
+			return err
+		} else if running {
+			_, err = srv.HotTar(ctx, w, java.BackupOptions{})
+			return err
+		}
+	case *bedrock.Bedrock:
+		if running && format == server.FormatZip {
+			return srv.HotZip(ctx, w)
+		} else if running {
+			return srv.HotTar(ctx, w)
+		}
+	}
+	if format == server.FormatZip {
+		return srv.Zip(w)
+	}
+	return srv.Tar(w)
+}
+
+// Restore instance backup, instance must be stopped
+func (manager *Manager) Restore(name string, r io.Reader, format server.ArchiveFormat) error {
+	current, err := manager.entry(name)
+	if err != nil {
+		return err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	if current.running() {
+		return server.ErrRunning
+	}
+	srv, err := manager.server(current)
+	if err != nil {
+		return err
+	}
+	return srv.Restore(r, format)
+}
+
+// Read properties file to map with dotted keys
+func ReadProperties(file string) (map[string]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	node, err := properties.NewParse(bytes.NewReader(data)).Values()
+	if err != nil {
+		return nil, err
+	}
+	return properties.Flat(node), nil
+}
+
+// Set keys in properties file in place keeping comments, order and other keys,
+// new keys are appended sorted in end of file. File is created if not exists
+func SetProperties(file string, values map[string]string) error {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	values, lines := maps.Clone(values), []string{}
+	for line := range strings.Lines(string(data)) {
+		text := strings.TrimLeft(line, " \t\f")
+		if text == "" || text[0] == '#' || text[0] == '!' {
+			lines = append(lines, line)
+			continue
+		}
+
+		sep := strings.IndexAny(text, "=:")
+		if sep == -1 {
+			lines = append(lines, line)
+			continue
+		}
+		key := strings.TrimSpace(text[:sep])
+		value, ok := values[key]
+		if !ok {
+			lines = append(lines, line)
+			continue
+		}
+		delete(values, key)
+		sep += len(text[sep+1:]) - len(strings.TrimLeft(text[sep+1:], " \t\f")) // Keep spaces after separator
+		lines = append(lines, fmt.Sprintf("%s%s\n", line[:len(line)-len(text)+sep+1], value))
+	}
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines[len(lines)-1] += "\n"
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		lines = append(lines, fmt.Sprintf("%s=%s\n", key, values[key]))
+	}
+	return os.WriteFile(file, []byte(strings.Join(lines, "")), 0644)
+}
@@ -0,0 +1,201 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+)
+
+var (
+	ErrPortInUse error = errors.New("port in use")           // Port claimed by other instance or listened by other process in host
+	ErrNoPort    error = errors.New("no free port in range") // All ports after wanted port are in use
+
+	// Port name to server.properties key
+	PropertiesPorts = map[string]string{
+		"server":   "server-port",
+		"serverv6": "server-portv6",
+		"query":    "query.port",
+		"rcon":     "rcon.port",
+	}
+)
+
+// Default ports to platform, Bedrock use 19132/19133 and Java 25565
+func DefaultPorts(platform Platform) []Port {
+	switch {
+	case platform == Bedrock, platform == Pocketmine:
+		return []Port{{Name: "server", Network: "udp", Port: 19132}, {Name: "serverv6", Network: "udp", Port: 19133}}
+	case platform == AllayMC:
+		return []Port{{Name: "server", Network: "udp", Port: 19132}}
+	case platform == Velocity:
+		return []Port{{Name: "server", Network: "tcp", Port: 25577}}
+	case platform.IsJava():
+		return []Port{{Name: "server", Network: "tcp", Port: 25565}}
+	}
+	return nil
+}
+
+// Check if port can be listened in host
+func PortFree(network string, port uint16) bool {
+	address := ":" + strconv.Itoa(int(port))
+	switch network {
+	case "udp":
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return false
+		}
+		return conn.Close() == nil
+	default:
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return false
+		}
+		return ln.Close() == nil
+	}
+}
+
+// Track ports claimed by instances and find free ports
+type Allocator struct {
+	Probe   func(network string, port uint16) bool // Check port free in host, default is [PortFree]
+	MaxPort uint16                                 // Last port to search, default is 65535
+
+	locker  sync.Mutex
+	claimed map[string]string // "<network>/<port>" to instance name
+}
+
+func portKey(network string, port uint16) string { return network + "/" + strconv.Itoa(int(port)) }
+
+func (alloc *Allocator) probe(network string, port uint16) bool {
+	if alloc.Probe != nil {
+		return alloc.Probe(network, port)
+	}
+	return PortFree(network, port)
+}
+
+// Instance name that claimed port, empty if free
+func (alloc *Allocator) Owner(network string, port uint16) string {
+	alloc.locker.Lock()
+	defer alloc.locker.Unlock()
+	return alloc.claimed[portKey(network, port)]
+}
+
+// Claim ports to instance without probe host, return [ErrPortInUse] if other
+// instance claimed any port
+func (alloc *Allocator) Claim(name string, ports []Port) error {
+	alloc.locker.Lock()
+	defer alloc.locker.Unlock()
+	if alloc.claimed == nil {
+		alloc.claimed = map[string]string{}
+	}
+	for _, port := range ports {
+		if owner, ok := alloc.claimed[portKey(port.Network, port.Port)]; ok && owner != name {
+			return fmt.Errorf("%w: %s/%d claimed by %q", ErrPortInUse, port.Network, port.Port, owner)
+		}
+	}
+	alloc.release(name)
+	for _, port := range ports {
+		alloc.claimed[portKey(port.Network, port.Port)] = name
+	}
+	return nil
+}
+
+// Replace instance ports with free ports, wanted port is used if free else
+// next port not claimed and free in host. Search start from 1024 to ports below
+func (alloc *Allocator) Allocate(name string, ports []Port) ([]Port, error) {
+	alloc.locker.Lock()
+	defer alloc.locker.Unlock()
+	if alloc.claimed == nil {
+		alloc.claimed = map[string]string{}
+	}
+	maxPort := alloc.MaxPort
+	if maxPort == 0 {
+		maxPort = 65535
+	}
+
+	chosen := map[string]bool{}
+	allocated := slices.Clone(ports)
+	for index, port := range allocated {
+		start, found := max(port.Port, 1024), false
+		for candidate := int(start); candidate <= int(maxPort); candidate++ {
+			key := portKey(port.Network, uint16(candidate))
+			if owner, claimed := alloc.claimed[key]; chosen[key] || (claimed && owner != name) {
+				continue
+			} else if !claimed && !alloc.probe(port.Network, uint16(candidate)) { // Ports of instance are not probed, server can be running
+				continue
+			}
+			chosen[key], allocated[index].Port, found = true, uint16(candidate), true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s %s from %d", ErrNoPort, port.Name, port.Network, start)
+		}
+	}
+
+	alloc.release(name)
+	for key := range chosen {
+		alloc.claimed[key] = name
+	}
+	return allocated, nil
+}
+
+// Remove ports claimed by instance
+func (alloc *Allocator) Release(name string) {
+	alloc.locker.Lock()
+	defer alloc.locker.Unlock()
+	alloc.release(name)
+}
+
+func (alloc *Allocator) release(name string) {
+	maps.DeleteFunc(alloc.claimed, func(_, owner string) bool { return owner == name })
+}
+
+// Check instance ports free in host before start
+func (alloc *Allocator) check(ports []Port) error {
+	for _, port := range ports {
+		if !alloc.probe(port.Network, port.Port) {
+			return fmt.Errorf("%w: %s/%d listened by other process", ErrPortInUse, port.Network, port.Port)
+		}
+	}
+	return nil
+}
+
+// AllayMC and Velocity ports are not written to config, return [ErrPortInUse] if allocated port is not wanted port
+func fixedPorts(instance Instance, allocated []Port) error {
+	if instance.HasProperties() || slices.Equal(instance.Ports, allocated) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s port not configurable and %+v in use", ErrPortInUse, instance.Platform, instance.Ports)
+}
+
+// Write instance ports to server.properties and Docker container
+func applyPorts(instance *Instance, proc exec.Proc) error {
+	if docker, ok := proc.(*exec.DockerContainer); ok {
+		docker.Ports = docker.Ports[:0]
+		for _, port := range instance.Ports {
+			docker.AddPort(port.Network, port.Port, port.Port)
+		}
+	}
+	if !instance.HasProperties() {
+		return nil
+	}
+
+	values := map[string]string{}
+	for _, port := range instance.Ports {
+		if key, ok := PropertiesPorts[port.Name]; ok {
+			values[key] = strconv.Itoa(int(port.Port))
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	file, err := instance.seedProperties()
+	if err != nil {
+		return err
+	}
+	return SetProperties(file, values)
+}
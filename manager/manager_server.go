@@ -0,0 +1,350 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"syscall"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock/allaymc"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock/pmmp"
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+// Process backend to instance
+func newProc(instance *Instance) exec.Proc {
+	switch instance.Backend {
+	case BackendProot:
+		return &exec.Proot{Rootfs: instance.Rootfs}
+	case BackendDocker:
+		docker := exec.NewDocker(nil) // Client created on Start
+		docker.ContainerName = instance.Container
+		if instance.Image != "" {
+			docker.Image = instance.Image
+		}
+		for _, folder := range []string{instance.VersionFolder, instance.JavaFolder, instance.Cwd} {
+			if folder != "" {
+				docker.Volumes = append(docker.Volumes, folder)
+			}
+		}
+		return docker
+	default:
+		return &exec.Os{}
+	}
+}
+
+// Make server struct with constructor of platform, if instance version is latest
+// it is replaced by version found
+func (manager *Manager) build(instance *Instance, proc exec.Proc) (server.Server, error) {
+	var srv server.Server
+	var start *exec.ProcExec
+	switch {
+	case instance.Platform == Bedrock:
+		version, err := manager.Versions.Bedrock(instance.Version)
+		if err != nil {
+			return nil, err
+		}
+		bed, err := bedrock.NewBedrock(version, instance.VersionFolder, instance.Cwd, instance.Upper, instance.Workdir)
+		if err != nil {
+			return nil, err
+		}
+		instance.Version, bed.PID, start, srv = version.Version, proc, &bed.ServerStart, bed
+	case instance.Platform == Pocketmine:
+		version, err := manager.Versions.Pocketmine(instance.Version)
+		if err != nil {
+			return nil, err
+		}
+		pocketmine, err := pmmp.NewPocketmine(version, instance.VersionFolder, instance.Cwd, instance.Upper, instance.Workdir)
+		if err != nil {
+			return nil, err
+		}
+		instance.Version, pocketmine.PID, start, srv = version.Version, proc, &pocketmine.ServerStart, pocketmine
+	case instance.Platform == AllayMC:
+		version, err := manager.Versions.AllayMC(instance.Version)
+		if err != nil {
+			return nil, err
+		}
+		allay, err := allaymc.NewAllayMC(version, instance.VersionFolder, instance.JavaFolder, instance.Cwd)
+		if err != nil {
+			return nil, err
+		}
+		instance.Version, allay.PID, start, srv = version.Version, proc, &allay.ServerStart, allay
+	case instance.Platform.IsJava():
+		version, err := manager.Versions.Java(instance.Platform, instance.Version)
+		if err != nil {
+			return nil, err
+		}
+		javaServer, err := java.NewServer(version, filepath.Join(instance.VersionFolder, string(instance.Platform)), instance.JavaFolder, instance.Cwd)
+		if err != nil {
+			return nil, err
+		}
+		instance.Version, javaServer.PID, start, srv = version.Version(), proc, &javaServer.ServerStart, javaServer
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrPlatform, instance.Platform)
+	}
+
+	if start.Environment == nil {
+		start.Environment = exec.Env{}
+	}
+	maps.Copy(start.Environment, instance.Env)
+	return srv, nil
+}
+
+// Server struct to instance, if not created download server and build
+func (manager *Manager) server(current *entry) (server.Server, error) {
+	if current.server != nil {
+		return current.server, nil
+	}
+	if current.proc == nil {
+		current.proc = newProc(current.instance)
+	}
+
+	manager.locker.Lock()
+	instance := *current.instance
+	manager.locker.Unlock()
+	srv, err := manager.build(&instance, current.proc)
+	if err != nil {
+		return nil, err
+	}
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	if current.instance.Version != instance.Version { // Pin latest version
+		current.instance.Version, current.instance.Updated = instance.Version, time.Now().UTC()
+		if err = manager.save(); err != nil {
+			return nil, err
+		}
+	}
+	current.server = srv
+	return srv, nil
+}
+
+// Process running, started by this manager or found by Reconcile
+func (current *entry) running() bool {
+	if current.adopted != nil {
+		if processAlive(current.adopted) {
+			return true
+		}
+		current.adopted = nil
+	}
+	return current.proc != nil && server.Running(current.proc)
+}
+
+// Install server files if not exists and return server struct
+func (manager *Manager) Install(name string) (server.Server, error) {
+	current, err := manager.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	return manager.server(current)
+}
+
+// Server struct to instance, same as [Manager.Install]
+func (manager *Manager) Server(name string) (server.Server, error) { return manager.Install(name) }
+
+// Instance process is running
+func (manager *Manager) Running(name string) bool {
+	current, err := manager.entry(name)
+	if err != nil {
+		return false
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	return current.running()
+}
+
+// Install if needed and start server
+func (manager *Manager) Start(ctx context.Context, name string) error {
+	current, err := manager.entry(name)
+	if err != nil {
+		return err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	if current.running() {
+		return exec.ErrRunning
+	}
+
+	manager.locker.Lock()
+	instance := *current.instance
+	manager.locker.Unlock()
+	if err = manager.Ports.Claim(name, instance.Ports); err != nil {
+		return err
+	} else if err = manager.Ports.check(instance.Ports); err != nil {
+		return err
+	}
+
+	srv, err := manager.server(current)
+	if err != nil {
+		return err
+	} else if err = applyPorts(&instance, current.proc); err != nil {
+		return err
+	} else if err = srv.Start(ctx); err != nil {
+		return err
+	}
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	if proc, ok := current.proc.(interface{ Pid() int }); ok {
+		current.instance.PID = proc.Pid()
+	}
+	return manager.save()
+}
+
+// Stop server, process found by Reconcile without console are stopped with signal
+func (manager *Manager) Stop(ctx context.Context, name string) (*server.StopStatus, error) {
+	current, err := manager.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	current.locker.Lock()
+	defer current.locker.Unlock()
+	if !current.running() {
+		return nil, exec.ErrNoRunning
+	}
+
+	var status *server.StopStatus
+	switch {
+	case current.adopted != nil:
+		status, err = stopProcess(ctx, current.adopted, manager.StopOptions)
+		current.adopted = nil
+	case current.server != nil:
+		status, err = current.server.Stop(ctx)
+	default: // Docker container found by Reconcile
+		status, err = server.Stop(ctx, current.proc, manager.StopOptions)
+	}
+
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	current.instance.PID = 0
+	return status, errors.Join(err, manager.save())
+}
+
+// Stop server if running and start again
+func (manager *Manager) Restart(ctx context.Context, name string) error {
+	if _, err := manager.Stop(ctx, name); err != nil && !errors.Is(err, exec.ErrNoRunning) {
+		return err
+	}
+	return manager.Start(ctx, name)
+}
+
+// Find instances processes still running from previous manager, Docker
+// containers are attached and host processes are tracked by PID without console
+func (manager *Manager) Reconcile() error {
+	manager.locker.Lock()
+	entries := make([]*entry, 0, len(manager.entries))
+	for _, current := range manager.entries {
+		entries = append(entries, current)
+	}
+	manager.locker.Unlock()
+
+	changed := false
+	for _, current := range entries {
+		current.locker.Lock()
+		if current.running() {
+			current.locker.Unlock()
+			continue
+		}
+
+		manager.locker.Lock()
+		instance := *current.instance
+		manager.locker.Unlock()
+		switch instance.Backend {
+		case BackendDocker:
+			docker := newProc(&instance).(*exec.DockerContainer)
+			if ok, err := docker.Reattach(context.Background()); err == nil && ok {
+				current.proc, current.server = docker, nil
+			}
+		default:
+			cwd := instance.Cwd
+			if instance.Backend == BackendProot {
+				cwd = "" // proot not change process folder
+			}
+			if current.adopted = findProcess(instance.PID, cwd); current.adopted == nil && instance.PID != 0 {
+				manager.locker.Lock()
+				current.instance.PID, changed = 0, true
+				manager.locker.Unlock()
+			}
+		}
+		current.locker.Unlock()
+	}
+
+	if !changed {
+		return nil
+	}
+	manager.locker.Lock()
+	defer manager.locker.Unlock()
+	return manager.save()
+}
+
+// Find process by PID, if cwd is set and system has /proc check process folder
+// to not get other process with reused PID
+func findProcess(pid int, cwd string) *os.Process {
+	if pid <= 0 {
+		return nil
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil || !processAlive(process) {
+		return nil
+	}
+	if link, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "cwd")); err == nil && cwd != "" {
+		if absCwd, err := filepath.Abs(cwd); err == nil && filepath.Clean(link) != absCwd {
+			return nil
+		}
+	}
+	return process
+}
+
+func processAlive(process *os.Process) bool {
+	if runtime.GOOS == "windows" {
+		return true // FindProcess open process handle, fail if not exists
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// Stop process without console with signal and kill after SignalGrace
+func stopProcess(ctx context.Context, process *os.Process, options server.StopOptions) (*server.StopStatus, error) {
+	if options.Signal == nil {
+		options.Signal = os.Interrupt
+	}
+	if options.SignalGrace <= 0 {
+		options.SignalGrace = server.DefaultSignalGrace
+	}
+
+	status := &server.StopStatus{Stage: server.StopSignal, ExitCode: -1} // Exit code is unknown to process not started by us
+	if err := process.Signal(options.Signal); err == nil {
+		grace, ticker := time.NewTimer(options.SignalGrace), time.NewTicker(100*time.Millisecond)
+		defer grace.Stop()
+		defer ticker.Stop()
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				break wait
+			case <-grace.C:
+				break wait
+			case <-ticker.C:
+				if !processAlive(process) {
+					return status, nil
+				}
+			}
+		}
+	}
+
+	status.Stage = server.StopKill
+	if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return status, err
+	}
+	return status, ctx.Err()
+}
@@ -0,0 +1,238 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock/allaymc"
+	"sirherobrine23.com.br/go-bds/go-bds/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+func TestManager(t *testing.T) {
+	root := t.TempDir()
+	registry := filepath.Join(root, "instances.json")
+	manager, err := Open(registry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instance := Instance{
+		Name:          "survival",
+		Platform:      Paper,
+		Version:       "1.21.1",
+		VersionFolder: filepath.Join(root, "versions"),
+		JavaFolder:    filepath.Join(root, "java"),
+		Cwd:           filepath.Join(root, "survival"),
+		Ports:         []Port{{Name: "server", Network: "tcp", Port: 25565}},
+	}
+	if err = manager.Create(Instance{Name: "../escape", Platform: Java, Cwd: root, VersionFolder: root, JavaFolder: root}); !errors.Is(err, ErrName) {
+		t.Errorf("expected invalid name, got %v", err)
+	} else if err = manager.Create(instance); err != nil {
+		t.Fatal(err)
+	} else if err = manager.Create(instance); !errors.Is(err, ErrExists) {
+		t.Errorf("expected exists error, got %v", err)
+	} else if err = manager.Update("survival", func(instance *Instance) error { instance.Version = "1.21.4"; return nil }); err != nil {
+		t.Fatal(err)
+	} else if err = manager.Update("survival", func(instance *Instance) error { instance.Backend = "vm"; return nil }); !errors.Is(err, ErrBackend) {
+		t.Errorf("expected backend error, got %v", err)
+	}
+
+	// Start process in server folder to be found after reopen
+	if runtime.GOOS == "windows" {
+		t.Skip("reconcile test require sleep command")
+	}
+	sleep := exec.Command("sleep", "30")
+	sleep.Dir = instance.Cwd
+	if err = sleep.Start(); err != nil {
+		t.Skipf("cannot start sleep: %s", err)
+	}
+	exited := make(chan struct{})
+	go func() { sleep.Wait(); close(exited) }()
+	defer sleep.Process.Kill()
+	if err = manager.Update("survival", func(instance *Instance) error { instance.PID = sleep.Process.Pid; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if manager, err = Open(registry, nil); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := manager.Get("survival")
+	if err != nil {
+		t.Fatal(err)
+	} else if saved.Version != "1.21.4" || saved.Backend != BackendOs || len(saved.Ports) != 1 {
+		t.Errorf("invalid instance from registry: %+v", saved)
+	} else if !manager.Running("survival") {
+		t.Fatal("running process not found by reconcile")
+	} else if err = manager.Delete("survival", true); !errors.Is(err, server.ErrRunning) {
+		t.Errorf("expected running error, got %v", err)
+	} else if err = manager.Backup(context.Background(), "survival", io.Discard, server.FormatTar); !errors.Is(err, server.ErrRunning) {
+		t.Errorf("expected running error to backup, got %v", err)
+	} else if err = manager.Restore("survival", strings.NewReader(""), server.FormatTar); !errors.Is(err, server.ErrRunning) {
+		t.Errorf("expected running error to restore, got %v", err)
+	}
+
+	if status, err := manager.Stop(context.Background(), "survival"); err != nil {
+		t.Fatal(err)
+	} else if status.Stage != server.StopSignal {
+		t.Errorf("expected stop with signal, got %s", status.Stage)
+	}
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("process not stopped")
+	}
+	if saved, _ = manager.Get("survival"); saved.PID != 0 {
+		t.Errorf("pid not cleaned: %d", saved.PID)
+	}
+
+	if err = manager.Delete("survival", true); err != nil {
+		t.Fatal(err)
+	} else if _, err = os.Stat(instance.Cwd); !os.IsNotExist(err) {
+		t.Errorf("server folder not removed: %v", err)
+	} else if len(manager.List()) != 0 {
+		t.Errorf("instance not removed: %v", manager.List())
+	}
+}
+
+func TestAllocator(t *testing.T) {
+	alloc := &Allocator{Probe: func(network string, port uint16) bool { return port != 19133 }}
+	first, err := alloc.Allocate("first", DefaultPorts(Bedrock))
+	if err != nil {
+		t.Fatal(err)
+	} else if first[0].Port != 19132 || first[1].Port != 19134 {
+		t.Errorf("invalid ports to first instance: %+v", first)
+	}
+	second, err := alloc.Allocate("second", DefaultPorts(Bedrock))
+	if err != nil {
+		t.Fatal(err)
+	} else if second[0].Port != 19135 || second[1].Port != 19136 {
+		t.Errorf("invalid ports to second instance: %+v", second)
+	} else if err = alloc.Claim("second", first); !errors.Is(err, ErrPortInUse) {
+		t.Errorf("expected port in use, got %v", err)
+	} else if alloc.Release("first"); alloc.Owner("udp", 19132) != "" {
+		t.Error("port not released")
+	}
+
+	// Velocity port is not written to velocity.toml, default port required
+	root := t.TempDir()
+	manager, err := Open(filepath.Join(root, "instances.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.Ports.Probe = func(string, uint16) bool { return true }
+	proxy := Instance{Name: "proxy", Platform: Velocity, Cwd: filepath.Join(root, "proxy"), VersionFolder: root, JavaFolder: root}
+	if err = manager.Create(proxy); err != nil {
+		t.Fatal(err)
+	}
+	proxy.Name, proxy.Cwd = "proxy2", filepath.Join(root, "proxy2")
+	if err = manager.Create(proxy); !errors.Is(err, ErrPortInUse) {
+		t.Errorf("expected port in use, got %v", err)
+	} else if manager.Ports.Owner("tcp", 25578) != "" {
+		t.Error("port claimed to rejected instance")
+	}
+	proxy.Ports = []Port{{Name: "server", Network: "tcp", Port: 25600}}
+	if err = manager.Create(proxy); !errors.Is(err, ErrConfig) {
+		t.Errorf("expected config error, got %v", err)
+	}
+
+	// Ports written to server.properties keeping other keys
+	file := filepath.Join(t.TempDir(), "server.properties")
+	if err = os.WriteFile(file, []byte("# Server name\nmotd=Hello\nquery.port=25565\nserver-port = 25565\n"), 0644); err != nil {
+		t.Fatal(err)
+	} else if err = SetProperties(file, map[string]string{"server-port": "25566", "rcon.port": "25575"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != "# Server name\nmotd=Hello\nquery.port=25565\nserver-port = 25566\nrcon.port=25575\n" {
+		t.Errorf("invalid properties:\n%s", data)
+	}
+
+	// Upper server.properties seeded from version folder
+	instance := Instance{Platform: Bedrock, Version: "1.21.70", VersionFolder: t.TempDir(), Cwd: t.TempDir(), Upper: t.TempDir()}
+	if lower := instance.lowerPropertiesFile(); lower != "" {
+		os.MkdirAll(filepath.Dir(lower), 0755)
+		if err = os.WriteFile(lower, []byte("motd=Dedicated Server\nserver-port=19132\n"), 0644); err != nil {
+			t.Fatal(err)
+		} else if file, err = instance.seedProperties(); err != nil {
+			t.Fatal(err)
+		} else if err = SetProperties(file, map[string]string{"server-port": "19140"}); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ = os.ReadFile(filepath.Join(instance.Upper, "server.properties")); string(data) != "motd=Dedicated Server\nserver-port=19140\n" {
+			t.Errorf("upper properties not seeded:\n%s", data)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := &Catalog{Folder: t.TempDir(), Offline: true}
+	remote := &RemoteVersions{Catalog: catalog}
+	if _, err := remote.Names(Paper); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected offline error, got %v", err)
+	}
+
+	saved := java.Versions{
+		java.GenericVersion{ServerVersion: "1.21.4", JVM: 65, DownloadURL: "https://example.com/1.21.4.jar"},
+		java.GenericVersion{ServerVersion: "1.20.1", JVM: 61, DownloadURL: "https://example.com/1.20.1.jar"},
+	}
+	if err := catalog.Save(Paper, saved, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := remote.Names(Paper); err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(names, []string{"1.20.1", "1.21.4"}) {
+		t.Errorf("invalid versions from catalog: %v", names)
+	} else if latest, err := remote.Java(Paper, Latest); err != nil || latest.JavaVersion() != 65 {
+		t.Errorf("invalid latest version: %v, %v", latest, err)
+	}
+
+	// Fetch only after catalog expire and save new versions
+	fetches := 0
+	catalog.Offline, catalog.MaxAge = false, time.Hour
+	versions := allaymc.Versions{}
+	fetch := func() error {
+		fetches++
+		versions = append(versions, &allaymc.Version{Version: "0.1.0", JavaVersion: 65, ServerURL: "https://example.com/allay.jar"})
+		return nil
+	}
+	if err := catalog.Save(AllayMC, versions, time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if err = remote.sync(AllayMC, &versions, fetch); err != nil {
+		t.Fatal(err)
+	} else if err = remote.sync(AllayMC, &versions, fetch); err != nil {
+		t.Fatal(err)
+	} else if fetches != 1 {
+		t.Errorf("expected one fetch, got %d", fetches)
+	}
+
+	// Upstream error use saved versions
+	failed := allaymc.Versions{}
+	remote = &RemoteVersions{Catalog: catalog}
+	catalog.MaxAge = time.Nanosecond
+	if err := remote.sync(AllayMC, &failed, func() error {
+		failed = append(failed, &allaymc.Version{Version: "0.2.0"})
+		return errors.New("upstream down")
+	}); err != nil {
+		t.Errorf("expected saved versions, got %v", err)
+	} else if len(failed) != 1 || failed[0].Version != "0.1.0" {
+		t.Errorf("invalid versions after fetch error: %+v", failed)
+	}
+	catalog.MaxAge = time.Hour
+
+	var fromDisk allaymc.Versions
+	if updated, err := catalog.Load(AllayMC, &fromDisk); err != nil {
+		t.Fatal(err)
+	} else if catalog.Expired(updated) || len(fromDisk) != 1 || fromDisk[0].ServerURL != "https://example.com/allay.jar" {
+		t.Errorf("fetched versions not saved: %v %+v", updated, fromDisk)
+	}
+}
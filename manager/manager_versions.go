@@ -0,0 +1,199 @@
+package manager
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock/allaymc"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock/pmmp"
+	"sirherobrine23.com.br/go-bds/go-bds/java"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/semver"
+)
+
+// Latest version alias, stable release to Bedrock
+const Latest = "latest"
+
+// Find server version to build instances, version "" or [Latest] return latest release
+type Versions interface {
+	Bedrock(version string) (*bedrock.Version, error)
+	Java(platform Platform, version string) (java.Version, error) // Java, Paper, Folia, Velocity, Purpur or Spigot
+	Pocketmine(version string) (*pmmp.Version, error)
+	AllayMC(version string) (*allaymc.Version, error)
+}
+
+var _ Versions = &RemoteVersions{}
+
+// Fetch versions from upstream on first use and keep in memory,
+// with Catalog versions are saved to disk and only new versions are fetched
+type RemoteVersions struct {
+	PHPScripts string   // Folder to clone PHP build scripts used by Pocketmine, required to Pocketmine
+	Catalog    *Catalog // Save fetched versions to disk, nil to keep only in memory
+
+	locker  sync.Mutex
+	updated map[Platform]time.Time
+	bedrock bedrock.Versions
+	java    map[Platform]*java.Versions
+	pmmp    pmmp.Versions
+	allaymc allaymc.Versions
+}
+
+func (remote *RemoteVersions) Bedrock(version string) (*bedrock.Version, error) {
+	remote.locker.Lock()
+	defer remote.locker.Unlock()
+	if err := remote.loadBedrock(); err != nil {
+		return nil, err
+	} else if version == "" || version == Latest {
+		if latest := remote.bedrock.LatestStable(); latest != nil {
+			return latest, nil
+		}
+		return nil, bedrock.ErrNoVersion
+	}
+	return remote.bedrock.Get(version)
+}
+
+func (remote *RemoteVersions) Java(platform Platform, version string) (java.Version, error) {
+	remote.locker.Lock()
+	defer remote.locker.Unlock()
+	versions, err := remote.loadJava(platform)
+	if err != nil {
+		return nil, err
+	}
+	return latestOrGet(versions, version, func(ver java.Version) string { return ver.Version() }, java.ErrNoVersion)
+}
+
+func (remote *RemoteVersions) Pocketmine(version string) (*pmmp.Version, error) {
+	remote.locker.Lock()
+	defer remote.locker.Unlock()
+	if err := remote.loadPocketmine(); err != nil {
+		return nil, err
+	}
+	return latestOrGet(remote.pmmp, version, func(ver *pmmp.Version) string { return ver.Version }, pmmp.ErrNoVersion)
+}
+
+func (remote *RemoteVersions) AllayMC(version string) (*allaymc.Version, error) {
+	remote.locker.Lock()
+	defer remote.locker.Unlock()
+	if err := remote.loadAllayMC(); err != nil {
+		return nil, err
+	}
+	return latestOrGet(remote.allaymc, version, func(ver *allaymc.Version) string { return ver.Version }, allaymc.ErrNoVersion)
+}
+
+// Versions names to platform sorted from oldest to newest
+func (remote *RemoteVersions) Names(platform Platform) ([]string, error) {
+	remote.locker.Lock()
+	defer remote.locker.Unlock()
+	names := []string{}
+	switch {
+	case platform == Bedrock:
+		if err := remote.loadBedrock(); err != nil {
+			return nil, err
+		}
+		for _, version := range remote.bedrock {
+			names = append(names, version.Version)
+		}
+	case platform == Pocketmine:
+		if err := remote.loadPocketmine(); err != nil {
+			return nil, err
+		}
+		for _, version := range remote.pmmp {
+			names = append(names, version.Version)
+		}
+	case platform == AllayMC:
+		if err := remote.loadAllayMC(); err != nil {
+			return nil, err
+		}
+		for _, version := range remote.allaymc {
+			names = append(names, version.Version)
+		}
+	default:
+		versions, err := remote.loadJava(platform)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			names = append(names, version.Version())
+		}
+	}
+	return names, nil
+}
+
+func (remote *RemoteVersions) loadBedrock() error {
+	return remote.sync(Bedrock, &remote.bedrock, remote.bedrock.FetchFromMinecraftDotNet)
+}
+
+func (remote *RemoteVersions) loadJava(platform Platform) (java.Versions, error) {
+	if remote.java == nil {
+		remote.java = map[Platform]*java.Versions{}
+	}
+	versions, ok := remote.java[platform]
+	if !ok {
+		versions = &java.Versions{}
+		remote.java[platform] = versions
+	}
+
+	var fetch func() error
+	switch platform {
+	case Java:
+		fetch = versions.FetchMojang
+	case Paper:
+		fetch = versions.FetchPaperVersions
+	case Folia:
+		fetch = versions.FetchFoliaVersions
+	case Velocity:
+		fetch = versions.FetchVelocityVersions
+	case Purpur:
+		fetch = versions.FetchPurpurVersions
+	case Spigot:
+		fetch = versions.FetchSpigotVersions
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrPlatform, platform)
+	}
+	if err := remote.sync(platform, versions, fetch); err != nil {
+		return nil, err
+	}
+	if platform == Velocity { // Catalogs saved before proxy flag
+		for index, version := range *versions {
+			if generic, ok := version.(java.GenericVersion); ok {
+				generic.Proxy, (*versions)[index] = true, generic
+			}
+		}
+	}
+	semver.Sort(*versions)
+	return *versions, nil
+}
+
+func (remote *RemoteVersions) loadPocketmine() error {
+	return remote.sync(Pocketmine, &remote.pmmp, func() error {
+		if remote.PHPScripts == "" {
+			return fmt.Errorf("%w: PHPScripts folder not set", pmmp.ErrNoVersion)
+		}
+		var phps pmmp.PHPs
+		if err := phps.FetchAllScripts(remote.PHPScripts); err != nil {
+			return err
+		} else if err = remote.pmmp.GetVersionsFromGithub(phps); err != nil {
+			return err
+		}
+		semver.Sort(remote.pmmp)
+		return nil
+	})
+}
+
+func (remote *RemoteVersions) loadAllayMC() error {
+	return remote.sync(AllayMC, &remote.allaymc, remote.allaymc.FetchFromGithub)
+}
+
+// Find version in sorted slice, last is latest
+func latestOrGet[T any](versions []T, version string, name func(T) string, notFound error) (ver T, err error) {
+	if len(versions) == 0 {
+		return ver, notFound
+	} else if version == "" || version == Latest {
+		return versions[len(versions)-1], nil
+	} else if index := slices.IndexFunc(versions, func(ver T) bool { return name(ver) == version }); index >= 0 {
+		return versions[index], nil
+	}
+	return ver, fmt.Errorf("%w: %s", notFound, version)
+}
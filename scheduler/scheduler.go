@@ -0,0 +1,214 @@
+// Run timed tasks to server like restarts, backups and console commands,
+// tasks run one at a time so restart and backup never race
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+var (
+	ErrPlayersOnline error = errors.New("task skipped, players online") // Task with SkipIfOnline and players in server
+	ErrNoSchedule    error = errors.New("task without schedule")        // Task Schedule is nil
+
+	DefaultMissedGrace = time.Minute // Delay to consider run as missed
+)
+
+// What to do if task lost run time, example host suspended or task blocked by other task
+type MissedPolicy int
+
+const (
+	MissedSkip    MissedPolicy = iota // Ignore lost runs and wait next time
+	MissedRunOnce                     // Run one time now and wait next time
+)
+
+// Task function
+type Action func(ctx context.Context, srv server.Server) error
+
+// Scheduled task
+type Task struct {
+	Name         string        // Task name to logs and errors
+	Schedule     Schedule      // Run times, [Every] or [Cron]
+	Action       Action        // Function to run
+	Jitter       time.Duration // Random delay added to every run, avoid many servers running same time
+	Missed       MissedPolicy  // Policy to lost runs
+	SkipIfOnline bool          // Skip run if any player online
+	LastRun      time.Time     // Last run, set to continue schedule after program restart
+
+	next time.Time
+}
+
+// Online players source, like [*PlayerTracker]
+type Players interface {
+	Online() []string // Players online now
+}
+
+// Task finished, Err is [ErrPlayersOnline] if skipped
+type Result struct {
+	Task     *Task
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Run tasks to server
+type Scheduler struct {
+	Server      server.Server // Server to run tasks
+	Players     Players       // Players to SkipIfOnline, if nil never skip
+	Tasks       []*Task       // Tasks
+	MissedGrace time.Duration // Delay to consider run missed, default is [DefaultMissedGrace]
+	OnResult    func(*Result) // Called after every task run or skip
+
+	locker sync.Mutex
+}
+
+// Add task to scheduler, can be called with scheduler running
+func (scheduler *Scheduler) Add(task *Task) error {
+	if task.Schedule == nil {
+		return ErrNoSchedule
+	}
+	scheduler.locker.Lock()
+	defer scheduler.locker.Unlock()
+	scheduler.Tasks = append(scheduler.Tasks, task)
+	return nil
+}
+
+// Run tasks until ctx done
+func (scheduler *Scheduler) Run(ctx context.Context) error {
+	grace := scheduler.MissedGrace
+	if grace <= 0 {
+		grace = DefaultMissedGrace
+	}
+
+	for {
+		now := time.Now()
+		scheduler.locker.Lock()
+		var task *Task
+		for _, current := range scheduler.Tasks {
+			if current.Schedule == nil {
+				continue
+			} else if current.next.IsZero() && !current.LastRun.IsZero() {
+				current.next = current.nextRun(current.LastRun)
+			} else if current.next.IsZero() {
+				current.next = current.nextRun(now)
+			}
+			if !current.next.IsZero() && (task == nil || current.next.Before(task.next)) {
+				task = current
+			}
+		}
+		scheduler.locker.Unlock()
+
+		// No tasks, wait ctx or check new tasks later
+		wait := time.Minute
+		if task != nil {
+			wait = time.Until(task.next)
+		}
+		if wait > 0 {
+			timer := time.NewTimer(min(wait, time.Minute))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+			continue // Recheck, tasks can be added while waiting
+		}
+
+		// Lost run
+		now = time.Now()
+		if now.Sub(task.next) > grace && task.Missed == MissedSkip {
+			task.next = task.nextRun(now)
+			continue
+		}
+
+		result := scheduler.run(ctx, task)
+		task.LastRun = result.Started
+		task.next = task.nextRun(time.Now())
+		if scheduler.OnResult != nil {
+			scheduler.OnResult(result)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// Run task now
+func (scheduler *Scheduler) RunTask(ctx context.Context, task *Task) *Result {
+	return scheduler.run(ctx, task)
+}
+
+func (scheduler *Scheduler) run(ctx context.Context, task *Task) *Result {
+	result := &Result{Task: task, Started: time.Now()}
+	defer func() { result.Duration = time.Since(result.Started) }()
+	if task.SkipIfOnline && scheduler.Players != nil && len(scheduler.Players.Online()) > 0 {
+		result.Err = ErrPlayersOnline
+	} else if task.Action != nil {
+		result.Err = task.Action(ctx, scheduler.Server)
+	}
+	return result
+}
+
+// Next run after time with jitter
+func (task *Task) nextRun(after time.Time) time.Time {
+	next := task.Schedule.Next(after)
+	if next.IsZero() {
+		return next
+	} else if task.Jitter > 0 {
+		next = next.Add(rand.N(task.Jitter))
+	}
+	return next
+}
+
+// Online players from log events, use with [logs.StreamProc]
+type PlayerTracker struct {
+	locker sync.RWMutex
+	online map[string]bool
+}
+
+// Update players from event, events not [logs.EventPlayer] are ignored
+func (tracker *PlayerTracker) Update(event logs.Event) {
+	if event.Type != logs.EventPlayer || event.Player == nil {
+		return
+	}
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+	if tracker.online == nil {
+		tracker.online = map[string]bool{}
+	}
+	switch event.Player.Action() {
+	case logs.Connect, logs.Spawned:
+		tracker.online[event.Player.Name()] = true
+	case logs.Disconnect, logs.Banned:
+		delete(tracker.online, event.Player.Name())
+	}
+}
+
+// Update players from events until channel closed, when server stop all players are removed
+func (tracker *PlayerTracker) Track(events <-chan logs.Event) {
+	for event := range events {
+		tracker.Update(event)
+	}
+	tracker.locker.Lock()
+	defer tracker.locker.Unlock()
+	clear(tracker.online)
+}
+
+// Players online
+func (tracker *PlayerTracker) Online() []string {
+	tracker.locker.RLock()
+	defer tracker.locker.RUnlock()
+	names := []string{}
+	for name := range tracker.online {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
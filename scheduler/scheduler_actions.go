@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/backup"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+// Send message to all players, "tellraw @a" to Java and "say" to others platforms
+func Broadcast(ctx context.Context, srv server.Server, message string) error {
+	command := "say " + message
+	if _, ok := srv.(*java.Server); ok {
+		text, _ := json.Marshal(map[string]string{"text": message, "color": "yellow"})
+		command = "tellraw @a " + string(text)
+	}
+	_, err := srv.RunCommand(ctx, command)
+	return err
+}
+
+// Run console commands in order
+func Command(commands ...string) Action {
+	return func(ctx context.Context, srv server.Server) error {
+		for _, command := range commands {
+			if _, err := srv.RunCommand(ctx, command); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Announce message before restart, %s is replaced by time left
+var RestartMessage = "Server restarting in %s"
+
+// Broadcast countdown, stop and start server. Countdown is time before restart to
+// announce, example []time.Duration{5 * time.Minute, time.Minute, 10 * time.Second}
+func Restart(countdown ...time.Duration) Action {
+	countdown = slices.Clone(countdown)
+	slices.SortFunc(countdown, func(a, b time.Duration) int { return cmp.Compare(b, a) })
+	return func(ctx context.Context, srv server.Server) error {
+		if server.Running(srv.Proc()) {
+			for index, left := range countdown {
+				if err := Broadcast(ctx, srv, fmt.Sprintf(RestartMessage, left)); err != nil {
+					return err
+				}
+
+				wait := left
+				if index+1 < len(countdown) {
+					wait -= countdown[index+1]
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+
+			if _, err := srv.Stop(ctx); err != nil {
+				return err
+			}
+		}
+		return srv.Start(ctx)
+	}
+}
+
+// Backup server folder to store, if server running saves are disabled while backup
+// with save-off to Java and save hold to Bedrock, with Bedrock only files reported
+// by "save query" are backed up from "worlds" folder, truncated to reported size.
+// Tags are added to snapshot with server version
+func Backup(store *backup.Store, folder string, tags map[string]string) Action {
+	return func(ctx context.Context, srv server.Server) (err error) {
+		snapshotTags := map[string]string{"version": srv.ServerVersion()}
+		maps.Copy(snapshotTags, tags)
+
+		fsys := os.DirFS(folder)
+		if server.Running(srv.Proc()) {
+			switch saves := srv.(type) {
+			case *java.Server:
+				if err = saves.SaveOff(ctx); err != nil {
+					return errors.Join(err, saves.SaveOn(context.WithoutCancel(ctx)))
+				}
+				defer func() { err = errors.Join(err, saves.SaveOn(context.WithoutCancel(ctx))) }()
+			case *bedrock.Bedrock:
+				files, resume, holdErr := saves.SaveHold(ctx)
+				if holdErr != nil {
+					return holdErr
+				}
+				defer func() { err = errors.Join(err, resume()) }()
+				if fsys, err = newSaveFS(fsys, files); err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = store.Backup(ctx, fsys, snapshotTags)
+		return err
+	}
+}
+
+// Server folder with "worlds" only with files from "save query", files truncated to reported size
+type saveFS struct {
+	fs.FS
+	files map[string]bedrock.SaveFile // Files reported with path with "worlds/" prefix
+	dirs  map[string]bool             // Folders with files reported
+}
+
+func newSaveFS(fsys fs.FS, files []bedrock.SaveFile) (*saveFS, error) {
+	save := &saveFS{FS: fsys, files: map[string]bedrock.SaveFile{}, dirs: map[string]bool{"worlds": true}}
+	for _, file := range files {
+		name := path.Join("worlds", file.Path)
+		if !fs.ValidPath(file.Path) || !strings.HasPrefix(name, "worlds/") {
+			return nil, fmt.Errorf("%w: invalid path %q", bedrock.ErrSaveQuery, file.Path)
+		}
+		save.files[name] = file
+		for dir := path.Dir(name); dir != "worlds" && dir != "."; dir = path.Dir(dir) {
+			save.dirs[dir] = true
+		}
+	}
+	return save, nil
+}
+
+func (save *saveFS) held(name string) bool {
+	return name == "worlds" || strings.HasPrefix(name, "worlds/")
+}
+
+func (save *saveFS) Open(name string) (fs.File, error) {
+	file, listed := save.files[name]
+	if !save.held(name) || save.dirs[name] {
+		return save.FS.Open(name)
+	} else if !listed {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return bedrock.OpenSaveFile(save.FS, file)
+}
+
+func (save *saveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(save.FS, name)
+	if err != nil || !save.held(name) {
+		return entries, err
+	}
+	return slices.DeleteFunc(entries, func(entry fs.DirEntry) bool {
+		name := path.Join(name, entry.Name())
+		_, listed := save.files[name]
+		return !(save.dirs[name] || listed)
+	}), nil
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrCron error = errors.New("invalid cron expression") // Cron expression cannot be parsed
+
+// Task run times
+type Schedule interface {
+	Next(after time.Time) time.Time // Next run after time
+}
+
+type interval time.Duration
+
+func (every interval) Next(after time.Time) time.Time { return after.Add(time.Duration(every)) }
+
+// Run task after every duration
+func Every(duration time.Duration) Schedule {
+	if duration <= 0 {
+		duration = time.Minute
+	}
+	return interval(duration)
+}
+
+// Cron schedule, fields are minute, hour, day of month, month and day of week
+type CronSchedule struct {
+	Minute, Hour, Day, Month, Weekday uint64 // Bits set to allowed values
+	Location                          *time.Location
+
+	anyDay, anyWeekday bool
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse standard cron expression with 5 fields or macros like "@daily", time in local time.
+//
+//	"*/15 * * * *" every 15 minutes
+//	"0 4 * * 1-5"  04:00 from monday to friday
+//	"30 3,15 1 * *" 03:30 and 15:30 in day 1 of month
+func Cron(expr string) (*CronSchedule, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q need 5 fields", ErrCron, expr)
+	}
+
+	cron := &CronSchedule{Location: time.Local, anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	var err error
+	if cron.Minute, err = cronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	} else if cron.Hour, err = cronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	} else if cron.Day, err = cronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	} else if cron.Month, err = cronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	} else if cron.Weekday, err = cronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if cron.Weekday&(1<<7) != 0 { // 7 is sunday too
+		cron.Weekday |= 1
+	}
+	return cron, nil
+}
+
+// Parse field with "*", lists "1,2", ranges "1-5" and steps "*/5" or "1-30/2"
+func cronField(field string, minValue, maxValue int) (bits uint64, err error) {
+	for part := range strings.SplitSeq(field, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step, start, end := 1, minValue, maxValue
+		if hasStep {
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%w: step %q", ErrCron, part)
+			}
+		}
+
+		if rangeText != "*" {
+			startText, endText, isRange := strings.Cut(rangeText, "-")
+			if start, err = strconv.Atoi(startText); err != nil {
+				return 0, fmt.Errorf("%w: value %q", ErrCron, part)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endText); err != nil {
+					return 0, fmt.Errorf("%w: value %q", ErrCron, part)
+				}
+			} else if hasStep {
+				end = maxValue
+			}
+		}
+		if start < minValue || end > maxValue || start > end {
+			return 0, fmt.Errorf("%w: %q out of range %d-%d", ErrCron, part, minValue, maxValue)
+		}
+		for value := start; value <= end; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func (cron CronSchedule) dayMatch(date time.Time) bool {
+	day, weekday := cron.Day&(1<<date.Day()) != 0, cron.Weekday&(1<<date.Weekday()) != 0
+	switch {
+	case cron.anyDay && cron.anyWeekday:
+		return true
+	case cron.anyDay:
+		return weekday
+	case cron.anyWeekday:
+		return day
+	default:
+		return day || weekday // Same as vixie cron
+	}
+}
+
+// Next time matching expression, zero time if not found in 5 years
+func (cron CronSchedule) Next(after time.Time) time.Time {
+	location := cron.Location
+	if location == nil {
+		location = time.Local
+	}
+	date := after.In(location).Truncate(time.Minute).Add(time.Minute)
+	limit := date.AddDate(5, 0, 0)
+
+	for date.Before(limit) {
+		switch {
+		case cron.Month&(1<<date.Month()) == 0:
+			date = time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, location)
+		case !cron.dayMatch(date):
+			date = time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, location)
+		case cron.Hour&(1<<date.Hour()) == 0:
+			date = time.Date(date.Year(), date.Month(), date.Day(), date.Hour()+1, 0, 0, 0, location)
+		case cron.Minute&(1<<date.Minute()) == 0:
+			date = date.Add(time.Minute)
+		default:
+			return date
+		}
+	}
+	return time.Time{}
+}
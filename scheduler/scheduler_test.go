@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/backup"
+	"sirherobrine23.com.br/go-bds/go-bds/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	bedrocklog "sirherobrine23.com.br/go-bds/go-bds/logs/bedrock"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+// Server only to record commands
+type fakeServer struct {
+	locker   sync.Mutex
+	commands []string
+}
+
+func (srv *fakeServer) Start(context.Context) error                      { return nil }
+func (srv *fakeServer) Stop(context.Context) (*server.StopStatus, error) { return nil, nil }
+func (srv *fakeServer) Wait() error                                      { return nil }
+func (srv *fakeServer) Tar(io.Writer) error                              { return nil }
+func (srv *fakeServer) Zip(io.Writer) error                              { return nil }
+func (srv *fakeServer) Restore(io.Reader, server.ArchiveFormat) error    { return nil }
+func (srv *fakeServer) ServerVersion() string                            { return "1.21.70" }
+func (srv *fakeServer) Proc() exec.Proc                                  { return nil }
+func (srv *fakeServer) RunCommand(_ context.Context, command string) ([]string, error) {
+	srv.locker.Lock()
+	defer srv.locker.Unlock()
+	srv.commands = append(srv.commands, command)
+	return nil, nil
+}
+
+func TestCron(t *testing.T) {
+	base := time.Date(2025, time.March, 1, 10, 7, 30, 0, time.UTC) // Saturday
+	tests := []struct {
+		expr string
+		next time.Time
+	}{
+		{"*/15 * * * *", time.Date(2025, time.March, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2025, time.March, 2, 4, 0, 0, 0, time.UTC)},
+		{"30 3,15 * * 1-5", time.Date(2025, time.March, 3, 3, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.March, 1, 11, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2025, time.March, 2, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		cron, err := Cron(test.expr)
+		if err != nil {
+			t.Errorf("%s: %s", test.expr, err)
+			continue
+		}
+		cron.Location = time.UTC
+		if next := cron.Next(base); !next.Equal(test.next) {
+			t.Errorf("%s: expected %s, got %s", test.expr, test.next, next)
+		}
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := Cron(expr); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	srv, tracker := &fakeServer{}, &PlayerTracker{}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	results := map[string][]error{}
+	var locker sync.Mutex
+	scheduler := &Scheduler{Server: srv, Players: tracker, OnResult: func(result *Result) {
+		locker.Lock()
+		defer locker.Unlock()
+		results[result.Task.Name] = append(results[result.Task.Name], result.Err)
+	}}
+	scheduler.Add(&Task{Name: "command", Schedule: Every(50 * time.Millisecond), Action: Command("save-all")})
+	scheduler.Add(&Task{Name: "skip", Schedule: Every(50 * time.Millisecond), SkipIfOnline: true, Action: Command("restart")})
+
+	// Missed with LastRun long time ago
+	scheduler.Add(&Task{Name: "missed", Schedule: Every(time.Hour), LastRun: time.Now().Add(-3 * time.Hour), Missed: MissedRunOnce, Action: Command("backup")})
+	scheduler.Add(&Task{Name: "lost", Schedule: Every(time.Hour), LastRun: time.Now().Add(-3 * time.Hour), Action: Command("lost")})
+
+	tracker.Update(logs.Event{Type: logs.EventPlayer, Player: &bedrocklog.BedrockPlayer{Username: "Steve", Actioned: logs.Connect}})
+	if online := tracker.Online(); !slices.Equal(online, []string{"Steve"}) {
+		t.Errorf("invalid online players: %v", online)
+	}
+
+	if err := scheduler.Run(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline: %v", err)
+	}
+
+	locker.Lock()
+	defer locker.Unlock()
+	if len(results["command"]) < 3 {
+		t.Errorf("command task runs %d times", len(results["command"]))
+	} else if len(results["skip"]) == 0 || results["skip"][0] != ErrPlayersOnline {
+		t.Errorf("task not skipped with players online: %v", results["skip"])
+	} else if len(results["missed"]) != 1 {
+		t.Errorf("missed task runs %d times, expected 1", len(results["missed"]))
+	} else if len(results["lost"]) != 0 || slices.Contains(srv.commands, "restart") || slices.Contains(srv.commands, "lost") {
+		t.Errorf("skipped tasks runned: %v", srv.commands)
+	}
+
+	// Broadcast
+	srv.commands = nil
+	if err := Broadcast(context.Background(), srv, "Hello"); err != nil || !slices.Equal(srv.commands, []string{"say Hello"}) {
+		t.Errorf("invalid broadcast: %v, %v", srv.commands, err)
+	}
+}
+
+func TestSaveFS(t *testing.T) {
+	folder := t.TempDir()
+	for name, content := range map[string]string{
+		"server.properties":                  "level-name=Bedrock level",
+		"worlds/Bedrock level/level.dat":     "level data",
+		"worlds/Bedrock level/db/000005.ldb": "db file written after hold",
+		"worlds/Bedrock level/db/LOCK":       "",
+		"worlds/Other/level.dat":             "other world",
+	} {
+		os.MkdirAll(filepath.Dir(filepath.Join(folder, name)), 0755)
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := backup.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := newSaveFS(os.DirFS(folder), []bedrock.SaveFile{{Path: "Bedrock level/level.dat", Size: 10}, {Path: "Bedrock level/db/000005.ldb", Size: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := store.Backup(context.Background(), fsys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]int64{}
+	for _, file := range snapshot.Files {
+		if !file.Mode.IsDir() {
+			files[file.Path] = file.Size
+		}
+	}
+	if len(files) != 3 || files["server.properties"] != 24 || files["worlds/Bedrock level/level.dat"] != 10 || files["worlds/Bedrock level/db/000005.ldb"] != 7 {
+		t.Errorf("invalid snapshot files: %v", files)
+	}
+
+	for _, name := range []string{"", ".", "..", "../server.properties", "/etc/passwd"} {
+		if _, err = newSaveFS(os.DirFS(folder), []bedrock.SaveFile{{Path: name, Size: 1}}); !errors.Is(err, bedrock.ErrSaveQuery) {
+			t.Errorf("path %q not rejected: %v", name, err)
+		}
+	}
+
+	if fsys, err = newSaveFS(os.DirFS(folder), []bedrock.SaveFile{{Path: "Bedrock level/level.dat", Size: 100}}); err != nil {
+		t.Fatal(err)
+	} else if _, err = store.Backup(context.Background(), fsys, nil); !errors.Is(err, bedrock.ErrSaveFile) {
+		t.Errorf("expected save file error, got %v", err)
+	}
+}
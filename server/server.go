@@ -0,0 +1,149 @@
+// Generic interface to manage Bedrock, Java, Pocketmine and AllayMC servers with same functions
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+)
+
+var (
+	ErrNoProc error = errors.New("server proc not defined") // Server struct without [exec.Proc]
+
+	DefaultStopGrace   = time.Minute      // Default time to wait server exit after stop command
+	DefaultSignalGrace = 15 * time.Second // Default time to wait server exit after signal
+)
+
+// Stage that ended server process
+type StopStage int
+
+const (
+	StopCommand StopStage = iota // Server exited after console stop command
+	StopSignal                   // Server exited after signal, default is SIGINT
+	StopKill                     // Server process killed
+)
+
+func (stage StopStage) String() string {
+	switch stage {
+	case StopCommand:
+		return "command"
+	case StopSignal:
+		return "signal"
+	case StopKill:
+		return "kill"
+	default:
+		return "unknown"
+	}
+}
+
+func (stage StopStage) MarshalText() ([]byte, error) { return []byte(stage.String()), nil }
+
+// Server stop result
+type StopStatus struct {
+	Stage    StopStage `json:"stage"`     // Stage that ended process
+	ExitCode int       `json:"exit_code"` // Process exit code
+}
+
+// Config to stop server gracefully
+type StopOptions struct {
+	Command     string        // Console command to stop server, default is "stop"
+	Grace       time.Duration // Time to wait after console command before send signal, default is [DefaultStopGrace]
+	Signal      os.Signal     // Signal to send after Grace, default is [os.Interrupt]
+	SignalGrace time.Duration // Time to wait after signal before kill process, default is [DefaultSignalGrace]
+}
+
+// Server implements basic functions to manage any server platform
+type Server interface {
+	Start(ctx context.Context) error                                  // Start server in background
+	Stop(ctx context.Context) (*StopStatus, error)                    // Stop server gracefully and wait process end
+	Wait() error                                                      // Wait server process end
+	RunCommand(ctx context.Context, command string) ([]string, error) // Write command and return console response
+	Tar(w io.Writer) error                                            // Make server backup with [*archive/tar.Writer]
+	Zip(w io.Writer) error                                            // Make server backup with [*archive/zip.Writer]
+	Restore(r io.Reader, format ArchiveFormat) error                  // Restore backup from Tar or Zip, server must be stopped
+	ServerVersion() string                                            // Server version
+	Proc() exec.Proc                                                  // Server process
+}
+
+// Stop server in three stages:
+//
+//  1. Write console command to stdin and wait Grace
+//  2. Send Signal and wait SignalGrace
+//  3. Kill process
+//
+// if ctx is done before process exit, skip to kill process and return ctx error with status
+func Stop(ctx context.Context, proc exec.Proc, options StopOptions) (*StopStatus, error) {
+	if proc == nil {
+		return nil, ErrNoProc
+	}
+
+	// Set default options
+	if options.Command == "" {
+		options.Command = "stop"
+	}
+	if options.Grace <= 0 {
+		options.Grace = DefaultStopGrace
+	}
+	if options.Signal == nil {
+		options.Signal = os.Interrupt
+	}
+	if options.SignalGrace <= 0 {
+		options.SignalGrace = DefaultSignalGrace
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		proc.Wait()
+		close(exited)
+	}()
+
+	status := &StopStatus{Stage: StopCommand}
+	exitStatus := func() (*StopStatus, error) {
+		status.ExitCode, _ = proc.ExitCode()
+		return status, nil
+	}
+
+	// Stage 1: console command
+	if _, err := proc.Write([]byte(options.Command + "\n")); err == nil {
+		select {
+		case <-exited:
+			return exitStatus()
+		case <-ctx.Done():
+			return killProc(ctx, proc, status, exited)
+		case <-time.After(options.Grace):
+		}
+	}
+
+	// Stage 2: signal
+	status.Stage = StopSignal
+	if err := proc.Signal(options.Signal); err == nil {
+		select {
+		case <-exited:
+			return exitStatus()
+		case <-ctx.Done():
+			return killProc(ctx, proc, status, exited)
+		case <-time.After(options.SignalGrace):
+		}
+	}
+
+	// Stage 3: kill
+	return killProc(ctx, proc, status, exited)
+}
+
+func killProc(ctx context.Context, proc exec.Proc, status *StopStatus, exited <-chan struct{}) (*StopStatus, error) {
+	status.Stage = StopKill
+	if err := proc.Kill(); err != nil {
+		select {
+		case <-exited:
+		default:
+			return status, err
+		}
+	}
+	<-exited
+	status.ExitCode, _ = proc.ExitCode()
+	return status, ctx.Err()
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+)
+
+var (
+	DefaultCommandWait  = 5 * time.Second        // Default time to wait first line of command response
+	DefaultCommandQuiet = 500 * time.Millisecond // Default time without new lines to end command response
+)
+
+// Config to capture console command response
+type CommandOptions struct {
+	Wait       time.Duration                        // Time to wait first response line, default is [DefaultCommandWait]
+	Quiet      time.Duration                        // Time without new lines to assume response ended, default is [DefaultCommandQuiet]
+	ParseLine  func(text string) (*logs.Line, bool) // Split log prefix from message, lines without prefix are returned as is
+	Terminator func(line *logs.Line) bool           // Return true if line is last line of response, example "Unknown command"
+}
+
+// Write command to server stdin and collect console lines printed in response.
+//
+// Lines are returned without log prefix when ParseLine is set, response ends
+// when output goes quiet, Terminator return true, process exit or ctx is done.
+func RunCommand(ctx context.Context, proc exec.Proc, command string, options CommandOptions) ([]string, error) {
+	if proc == nil {
+		return nil, ErrNoProc
+	}
+
+	if options.Wait <= 0 {
+		options.Wait = DefaultCommandWait
+	}
+	if options.Quiet <= 0 {
+		options.Quiet = DefaultCommandQuiet
+	}
+
+	// Fork stdout before write command to not lose response
+	stdout, err := proc.StdoutFork()
+	if err != nil {
+		return nil, err
+	}
+	defer stdout.Close()
+
+	var scanErr error
+	done, lines := make(chan struct{}), make(chan string)
+	defer close(done)
+	go func() {
+		defer close(lines)
+		scan := bufio.NewScanner(stdout)
+		scan.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), logs.MaxLineSize) // save query print all files in one line
+		defer func() { scanErr = scan.Err() }()
+		for scan.Scan() {
+			select {
+			case lines <- scan.Text():
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if _, err := proc.Write([]byte(strings.TrimSuffix(command, "\n") + "\n")); err != nil {
+		return nil, err
+	}
+
+	response := []string{}
+	timer := time.NewTimer(options.Wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return response, ctx.Err()
+		case <-timer.C:
+			return response, nil
+		case text, ok := <-lines:
+			if !ok {
+				return response, scanErr
+			}
+
+			if options.ParseLine != nil {
+				if line, ok := options.ParseLine(text); ok {
+					response = append(response, line.Message)
+					if options.Terminator != nil && options.Terminator(line) {
+						return response, nil
+					}
+					timer.Reset(options.Quiet)
+					continue
+				}
+			}
+			response = append(response, text)
+			if options.Terminator != nil && options.Terminator(&logs.Line{Message: text}) {
+				return response, nil
+			}
+			timer.Reset(options.Quiet)
+		}
+	}
+}
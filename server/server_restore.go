@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	"sirherobrine23.com.br/go-bds/go-bds/utils/archive"
+)
+
+var (
+	ErrRunning       error = errors.New("server running, stop before restore")    // Restore with server process running
+	ErrArchivePath   error = archive.ErrPath                                      // Absolute path, ".." or symlink escaping target
+	ErrArchiveLayout error = errors.New("archive not have expected files")        // Archive not is backup from this server
+	ErrFormat        error = errors.New("invalid archive format, use tar or zip") // Format not supported
+
+	DefaultRestoreGrace = 2 * time.Minute // Time restored server must run to keep files if started line is not printed
+)
+
+// Backup archive format
+type ArchiveFormat string
+
+const (
+	FormatTar ArchiveFormat = "tar" // Tar from Tar(w), gzip compressed tar is detected
+	FormatZip ArchiveFormat = "zip" // Zip from Zip(w)
+)
+
+// Check if archive files is backup from server, files are slash separated
+type Layout func(files []string) error
+
+// Accept archive if any file or folder match with [path.Match] pattern, example "server.properties", "worlds" or "*/level.dat"
+func LayoutAny(names ...string) Layout {
+	return func(files []string) error {
+		for _, file := range files {
+			if slices.ContainsFunc(names, func(name string) bool {
+				ok, _ := path.Match(name, file)
+				return ok || strings.HasPrefix(file, name+"/")
+			}) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: expected any of %s", ErrArchiveLayout, strings.Join(names, ", "))
+	}
+}
+
+// Files replaced by restore, kept until [*RestorePoint.Commit] or [*RestorePoint.Rollback]
+type RestorePoint struct {
+	Target   string   // Folder restored
+	OldFiles string   // Folder with replaced files
+	Entries  []string // Files and folders in Target root replaced by archive
+
+	locker sync.Mutex
+	done   chan struct{} // Closed after server started or exited
+}
+
+// Check if process is running with backend liveness check
+func Running(proc exec.Proc) bool {
+	return proc != nil && proc.Running()
+}
+
+// Extract archive to temporary folder, check paths and layout and replace files in target.
+// Files in target root not in archive are kept, replaced files are moved to rollback folder
+func RestoreArchive(r io.Reader, format ArchiveFormat, target string, layout Layout) (*RestorePoint, error) {
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return nil, err
+	}
+	parent, base := filepath.Dir(filepath.Clean(target)), filepath.Base(filepath.Clean(target))
+	staging, err := os.MkdirTemp(parent, "."+base+"-restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+
+	var files []string
+	switch format {
+	case FormatTar:
+		files, err = archive.Tar(r, staging, 0)
+	case FormatZip:
+		files, err = archive.Zip(r, staging, 0)
+	default:
+		err = fmt.Errorf("%w: %q", ErrFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	} else if len(files) == 0 {
+		return nil, fmt.Errorf("%w: archive is empty", ErrArchiveLayout)
+	} else if layout != nil {
+		if err = layout(files); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return nil, err
+	}
+	point := &RestorePoint{Target: target, Entries: []string{}}
+	if point.OldFiles, err = os.MkdirTemp(parent, "."+base+"-rollback-*"); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if _, err = os.Lstat(filepath.Join(target, name)); err == nil {
+			if err = os.Rename(filepath.Join(target, name), filepath.Join(point.OldFiles, name)); err != nil {
+				return nil, errors.Join(err, point.Rollback())
+			}
+		}
+		point.Entries = append(point.Entries, name)
+		if err = os.Rename(filepath.Join(staging, name), filepath.Join(target, name)); err != nil {
+			return nil, errors.Join(err, point.Rollback())
+		}
+	}
+	return point, nil
+}
+
+// Rollback files if start fail, else watch server in background: restore is committed
+// when parse emit [logs.EventStarted] or process run for [DefaultRestoreGrace] and
+// rollback if process exit before. parse can be nil to only wait grace, return start error
+func (point *RestorePoint) Started(err error, proc exec.Proc, parse logs.StreamParse) error {
+	if point == nil {
+		return err
+	} else if err != nil {
+		return errors.Join(err, point.Rollback())
+	}
+
+	point.locker.Lock()
+	defer point.locker.Unlock()
+	if point.OldFiles != "" && point.done == nil {
+		point.done = make(chan struct{})
+		go point.watch(proc, parse)
+	}
+	return nil
+}
+
+// Wait restore committed or rolled back after [RestorePoint.Started]
+func (point *RestorePoint) Wait() {
+	if point == nil {
+		return
+	}
+	point.locker.Lock()
+	done := point.done
+	point.locker.Unlock()
+	if done != nil {
+		<-done
+	}
+}
+
+func (point *RestorePoint) watch(proc exec.Proc, parse logs.StreamParse) {
+	defer close(point.done)
+	exited := make(chan struct{})
+	go func() {
+		proc.Wait()
+		close(exited)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := make(chan struct{})
+	if parse != nil {
+		if events, err := logs.StreamProc(ctx, proc, parse); err == nil {
+			go func() {
+				for event := range events {
+					if event.Type == logs.EventStarted {
+						close(started)
+						return
+					}
+				}
+			}()
+		}
+	}
+
+	select {
+	case <-started:
+		point.Commit()
+	case <-time.After(DefaultRestoreGrace):
+		point.Commit()
+	case <-exited:
+		point.Rollback()
+	}
+}
+
+// Delete old files, call after server start with restored files
+func (point *RestorePoint) Commit() error {
+	if point == nil {
+		return nil
+	}
+	point.locker.Lock()
+	defer point.locker.Unlock()
+	if point.OldFiles == "" {
+		return nil
+	}
+	err := os.RemoveAll(point.OldFiles)
+	point.OldFiles = ""
+	return err
+}
+
+// Remove restored files and move old files back to target
+func (point *RestorePoint) Rollback() error {
+	if point == nil {
+		return nil
+	}
+	point.locker.Lock()
+	defer point.locker.Unlock()
+	if point.OldFiles == "" {
+		return nil
+	}
+
+	errs := []error{}
+	for _, name := range point.Entries {
+		if err := os.RemoveAll(filepath.Join(point.Target, name)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	entries, err := os.ReadDir(point.OldFiles)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(point.OldFiles, entry.Name()), filepath.Join(point.Target, entry.Name())); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, os.RemoveAll(point.OldFiles))
+		point.OldFiles = ""
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+)
+
+var _ exec.Proc = &fakeProc{}
+
+// Fake process to test stop stages without real server
+type fakeProc struct {
+	ExitOnCommand bool // Exit after write stop command
+	ExitOnSignal  bool // Exit after receive signal
+
+	Responses map[string][]string // Lines printed to stdout after command
+
+	once   sync.Once
+	exited chan struct{}
+	code   int
+	stdin  strings.Builder
+	stdout exec.Writers
+}
+
+func newFakeProc(onCommand, onSignal bool) *fakeProc {
+	return &fakeProc{ExitOnCommand: onCommand, ExitOnSignal: onSignal, exited: make(chan struct{})}
+}
+
+func (proc *fakeProc) exit(code int) {
+	proc.once.Do(func() {
+		proc.code = code
+		close(proc.exited)
+	})
+}
+
+func (proc *fakeProc) Start(exec.ProcExec) error { return nil }
+func (proc *fakeProc) Close() error              { return proc.Kill() }
+func (proc *fakeProc) Kill() error               { proc.exit(137); return nil }
+func (proc *fakeProc) Wait() error               { <-proc.exited; return nil }
+func (proc *fakeProc) ExitCode() (int, error)    { <-proc.exited; return proc.code, nil }
+func (proc *fakeProc) Running() bool {
+	select {
+	case <-proc.exited:
+		return false
+	default:
+		return true
+	}
+}
+func (proc *fakeProc) Signal(os.Signal) error {
+	if proc.ExitOnSignal {
+		proc.exit(130)
+	}
+	return nil
+}
+func (proc *fakeProc) Write(p []byte) (int, error) {
+	proc.stdin.Write(p)
+	if proc.ExitOnCommand && strings.TrimSpace(string(p)) == "stop" {
+		proc.exit(0)
+	}
+	if lines, ok := proc.Responses[strings.TrimSpace(string(p))]; ok {
+		go func() {
+			for _, line := range lines {
+				proc.stdout.Write([]byte(line + "\n"))
+			}
+		}()
+	}
+	return len(p), nil
+}
+func (proc *fakeProc) AppendToStdin(io.Reader) error      { return nil }
+func (proc *fakeProc) AppendToStdout(io.Writer) error     { return nil }
+func (proc *fakeProc) AppendToStderr(io.Writer) error     { return nil }
+func (proc *fakeProc) StdinFork() (io.WriteCloser, error) { return nil, io.EOF }
+func (proc *fakeProc) StdoutFork() (io.ReadCloser, error) {
+	r, w := io.Pipe()
+	proc.stdout.AddNewWriter(w)
+	return r, nil
+}
+func (proc *fakeProc) StderrFork() (io.ReadCloser, error) { return nil, io.EOF }
+
+func TestStop(t *testing.T) {
+	options := StopOptions{Grace: 50 * time.Millisecond, SignalGrace: 50 * time.Millisecond}
+	tests := []struct {
+		Name     string
+		Proc     *fakeProc
+		Stage    StopStage
+		ExitCode int
+	}{
+		{"Command", newFakeProc(true, true), StopCommand, 0},
+		{"Signal", newFakeProc(false, true), StopSignal, 130},
+		{"Kill", newFakeProc(false, false), StopKill, 137},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			status, err := Stop(context.Background(), test.Proc, options)
+			if err != nil {
+				t.Fatal(err)
+			} else if status.Stage != test.Stage {
+				t.Errorf("expected stage %s, got %s", test.Stage, status.Stage)
+			} else if status.ExitCode != test.ExitCode {
+				t.Errorf("expected exit code %d, got %d", test.ExitCode, status.ExitCode)
+			}
+		})
+	}
+
+	t.Run("Context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		status, err := Stop(ctx, newFakeProc(false, false), StopOptions{Grace: time.Hour})
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected context error, got %v", err)
+		} else if status.Stage != StopKill {
+			t.Errorf("expected kill stage, got %s", status.Stage)
+		}
+	})
+}
+
+func TestRunCommand(t *testing.T) {
+	proc := newFakeProc(false, false)
+	proc.Responses = map[string][]string{
+		"list": {"[21:41:40] [Server thread/INFO]: There are 1 of a max of 20 players online: Sirherobrine"},
+		"foo": {
+			"[21:41:41] [Server thread/INFO]: Unknown or incomplete command, see below for error",
+			"[21:41:41] [Server thread/INFO]: foo<--[HERE]",
+			"[21:41:41] [Server thread/INFO]: not response",
+		},
+	}
+
+	options := CommandOptions{
+		Quiet: 50 * time.Millisecond,
+		ParseLine: func(text string) (*logs.Line, bool) {
+			_, message, ok := strings.Cut(text, "]: ")
+			return &logs.Line{Message: message}, ok
+		},
+		Terminator: func(line *logs.Line) bool { return strings.HasSuffix(line.Message, "<--[HERE]") },
+	}
+
+	lines, err := RunCommand(context.Background(), proc, "list", options)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(lines) != 1 || lines[0] != "There are 1 of a max of 20 players online: Sirherobrine" {
+		t.Errorf("invalid list response: %q", lines)
+	}
+
+	lines, err = RunCommand(context.Background(), proc, "foo", options)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(lines) != 2 {
+		t.Errorf("response not ended in terminator: %q", lines)
+	}
+
+	// Long line, example "save query" in world with many files
+	long := strings.Repeat("db/000001.ldb:1024, ", 8<<10)
+	proc.Responses["save query"] = []string{"[21:41:42] [Server thread/INFO]: " + long, "[21:41:42] [Server thread/INFO]: foo<--[HERE]"}
+	if lines, err = RunCommand(context.Background(), proc, "save query", options); err != nil {
+		t.Fatal(err)
+	} else if len(lines) != 2 || lines[0] != long {
+		t.Errorf("long line not returned: %d lines", len(lines))
+	}
+
+	options.Wait = 50 * time.Millisecond
+	if lines, err = RunCommand(context.Background(), proc, "bar", options); err != nil {
+		t.Fatal(err)
+	} else if len(lines) != 0 {
+		t.Errorf("unexpected response: %q", lines)
+	}
+}
+
+func makeTar(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buff bytes.Buffer
+	tarball := tar.NewWriter(&buff)
+	for name, content := range files {
+		if err := tarball.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tarball.Write([]byte(content))
+	}
+	tarball.Close()
+	return &buff
+}
+
+func TestRestoreArchive(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "server")
+	os.MkdirAll(filepath.Join(target, "worlds/old"), 0755)
+	os.WriteFile(filepath.Join(target, "server.properties"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(target, "bedrock_server"), []byte("bin"), 0755)
+	layout := LayoutAny("worlds", "server.properties")
+
+	// Invalid archives
+	if _, err := RestoreArchive(makeTar(t, map[string]string{"../escape": "x"}), FormatTar, target, layout); !errors.Is(err, ErrArchivePath) {
+		t.Errorf("path traversal not detected: %v", err)
+	} else if _, err = RestoreArchive(makeTar(t, map[string]string{"world/level.dat": "x"}), FormatTar, target, layout); !errors.Is(err, ErrArchiveLayout) {
+		t.Errorf("invalid layout not detected: %v", err)
+	} else if _, err = RestoreArchive(strings.NewReader("not tar"), "rar", target, layout); !errors.Is(err, ErrFormat) {
+		t.Errorf("invalid format not detected: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "server.properties")); string(data) != "old" {
+		t.Fatalf("target changed by invalid archive: %q", data)
+	}
+
+	// Chain of symlinks escaping target
+	var chain bytes.Buffer
+	tarball := tar.NewWriter(&chain)
+	tarball.WriteHeader(&tar.Header{Name: "d", Linkname: ".", Typeflag: tar.TypeSymlink})
+	tarball.WriteHeader(&tar.Header{Name: "e", Linkname: "d/..", Typeflag: tar.TypeSymlink})
+	tarball.WriteHeader(&tar.Header{Name: "e/evil.txt", Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+	tarball.Write([]byte("evil"))
+	tarball.Close()
+	if _, err := RestoreArchive(&chain, FormatTar, target, layout); !errors.Is(err, ErrArchivePath) {
+		t.Errorf("symlink chain not detected: %v", err)
+	} else if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(target), "*", "evil.txt")); len(matches) > 0 {
+		t.Errorf("file written outside target: %v", matches)
+	} else if _, err = os.Stat(filepath.Join(filepath.Dir(target), "evil.txt")); !os.IsNotExist(err) {
+		t.Errorf("file written outside target: %v", err)
+	}
+
+	// Restore and rollback
+	point, err := RestoreArchive(makeTar(t, map[string]string{"server.properties": "new", "worlds/new/level.dat": "level"}), FormatTar, target, layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "server.properties")); string(data) != "new" {
+		t.Errorf("file not restored: %q", data)
+	} else if _, err = os.Stat(filepath.Join(target, "worlds/old")); !os.IsNotExist(err) {
+		t.Errorf("old world not replaced: %v", err)
+	} else if _, err = os.Stat(filepath.Join(target, "bedrock_server")); err != nil {
+		t.Errorf("file not in archive removed: %v", err)
+	}
+	if err = point.Started(errors.New("start fail"), nil, nil); err == nil {
+		t.Error("start error not returned")
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "server.properties")); string(data) != "old" {
+		t.Errorf("file not rollback: %q", data)
+	} else if _, err = os.Stat(filepath.Join(target, "worlds/old")); err != nil {
+		t.Errorf("old world not rollback: %v", err)
+	}
+
+	// Zip and commit
+	var buff bytes.Buffer
+	wr := zip.NewWriter(&buff)
+	w, _ := wr.Create("worlds/new/level.dat")
+	w.Write([]byte("level"))
+	wr.Close()
+	if point, err = RestoreArchive(&buff, FormatZip, target, layout); err != nil {
+		t.Fatal(err)
+	}
+	proc := newFakeProc(false, false)
+	proc.Responses = map[string][]string{"list": {"Done"}}
+	if err = point.Started(nil, proc, startedParse{}); err != nil {
+		t.Fatal(err)
+	}
+	for started := false; !started; {
+		select {
+		case <-point.done:
+			started = true
+		case <-time.After(10 * time.Millisecond):
+			proc.Write([]byte("list\n")) // Print started line after stdout is attached
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(target, "worlds/new/level.dat")); string(data) != "level" {
+		t.Errorf("zip not restored: %q", data)
+	} else if entries, _ := os.ReadDir(filepath.Dir(target)); len(entries) != 1 {
+		t.Errorf("rollback or staging folders not removed: %v", entries)
+	}
+
+	// Server exit before started line
+	if point, err = RestoreArchive(makeTar(t, map[string]string{"server.properties": "crash"}), FormatTar, target, layout); err != nil {
+		t.Fatal(err)
+	}
+	proc = newFakeProc(false, false)
+	if err = point.Started(nil, proc, startedParse{}); err != nil {
+		t.Fatal(err)
+	}
+	proc.exit(1)
+	point.Wait()
+	if data, _ := os.ReadFile(filepath.Join(target, "server.properties")); string(data) != "old" {
+		t.Errorf("file not rollback after exit: %q", data)
+	}
+}
+
+// Emit started event on "Done" line
+type startedParse struct{}
+
+func (startedParse) Flush() []logs.Event { return nil }
+func (startedParse) Next(line string) []logs.Event {
+	if line == "Done" {
+		return []logs.Event{{Type: logs.EventStarted}}
+	}
+	return nil
+}
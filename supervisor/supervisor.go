@@ -0,0 +1,329 @@
+// Keep server process running, restart with backoff when process exit
+// and stop restarting if process crash many times in short time
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+var (
+	ErrCrashLoop error = errors.New("process crash loop")     // Process failed CrashLoopCount times in CrashLoopWindow
+	ErrPolicy    error = errors.New("invalid restart policy") // Policy name not exists
+
+	DefaultBackoff         = time.Second     // First restart delay
+	DefaultMaxBackoff      = time.Minute     // Max restart delay
+	DefaultCrashLoopCount  = 5               // Failures to consider crash loop
+	DefaultCrashLoopWindow = 5 * time.Minute // Time window to count failures
+	DefaultLogLines        = 50              // Log lines kept from last run
+)
+
+// When restart process
+type Policy int
+
+const (
+	Never     Policy = iota // Never restart
+	OnFailure               // Restart if exit code is not 0 or process not started
+	Always                  // Restart always, if exit code is 0 too
+)
+
+var policyNames = []string{"never", "on-failure", "always"}
+
+func (policy Policy) String() string {
+	if policy < 0 || int(policy) >= len(policyNames) {
+		return fmt.Sprintf("Policy(%d)", int(policy))
+	}
+	return policyNames[policy]
+}
+
+func (policy Policy) MarshalText() ([]byte, error) { return []byte(policy.String()), nil }
+func (policy *Policy) UnmarshalText(data []byte) error {
+	for index, name := range policyNames {
+		if name == string(data) {
+			*policy = Policy(index)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrPolicy, data)
+}
+
+// Supervisor lifecycle event type
+type EventType int
+
+const (
+	EventStarted    EventType = iota // Process started
+	EventExited                      // Process exited or failed to start, Crash have exit info
+	EventRestarting                  // Waiting Delay to start process again
+	EventCrashLoop                   // Process failed too many times, supervisor stopped
+	EventStopped                     // Supervisor stopped by ctx
+)
+
+var eventNames = []string{"started", "exited", "restarting", "crash-loop", "stopped"}
+
+func (event EventType) String() string {
+	if event < 0 || int(event) >= len(eventNames) {
+		return fmt.Sprintf("EventType(%d)", int(event))
+	}
+	return eventNames[event]
+}
+
+func (event EventType) MarshalText() ([]byte, error) { return []byte(event.String()), nil }
+
+// Supervisor lifecycle event
+type Event struct {
+	Type    EventType     `json:"type"`
+	Time    time.Time     `json:"time"`
+	Attempt int           `json:"attempt"`         // Process start count, first start is 1
+	Delay   time.Duration `json:"delay,omitempty"` // Delay before restart, only to EventRestarting
+	Crash   *Crash        `json:"crash,omitempty"` // Exit info to EventExited and EventCrashLoop
+}
+
+// Process exit info
+type Crash struct {
+	ExitCode int                  `json:"exit_code"`
+	Time     time.Time            `json:"time"`
+	Uptime   time.Duration        `json:"uptime"`
+	Lines    []string             `json:"lines"`            // Last log lines from stdout and stderr
+	Reason   *logs.ErrorReference `json:"reason,omitempty"` // Last error found by log parser
+	Err      error                `json:"-"`                // Error from start process
+}
+
+// Process exited with error
+func (crash *Crash) Failed() bool { return crash.Err != nil || crash.ExitCode != 0 }
+
+// Keep process running with restart policy
+type Supervisor struct {
+	Proc            exec.Proc               // Process to supervise
+	Options         exec.ProcExec           // Process start options
+	Policy          Policy                  // Restart policy
+	Backoff         time.Duration           // First restart delay, doubled to every failure in CrashLoopWindow, default is [DefaultBackoff]
+	MaxBackoff      time.Duration           // Max restart delay, default is [DefaultMaxBackoff]
+	CrashLoopCount  int                     // Failures in CrashLoopWindow to stop restarting, default is [DefaultCrashLoopCount]
+	CrashLoopWindow time.Duration           // Time window to count failures, default is [DefaultCrashLoopWindow]
+	LogLines        int                     // Log lines kept to Crash, default is [DefaultLogLines]
+	Parse           func() logs.StreamParse // New log parser to every start, if nil Crash.Reason is always nil
+	StopOptions     server.StopOptions      // Options to stop process when ctx done
+	OnEvent         func(Event)             // Called to every lifecycle event
+
+	locker    sync.Mutex
+	lastCrash *Crash
+}
+
+// Last process exit, nil if process never exited
+func (supervisor *Supervisor) LastCrash() *Crash {
+	supervisor.locker.Lock()
+	defer supervisor.locker.Unlock()
+	return supervisor.lastCrash
+}
+
+// Start process and restart with policy until ctx done or crash loop,
+// when ctx done stop process with StopOptions and return ctx error
+func (supervisor *Supervisor) Run(ctx context.Context) error {
+	if supervisor.Proc == nil {
+		return server.ErrNoProc
+	}
+	backoff, maxBackoff := supervisor.Backoff, supervisor.MaxBackoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	loopCount, loopWindow := supervisor.CrashLoopCount, supervisor.CrashLoopWindow
+	if loopCount <= 0 {
+		loopCount = DefaultCrashLoopCount
+	}
+	if loopWindow <= 0 {
+		loopWindow = DefaultCrashLoopWindow
+	}
+
+	var failures []time.Time
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			supervisor.emit(Event{Type: EventStopped, Attempt: attempt - 1})
+			return err
+		}
+
+		crash, err := supervisor.run(ctx, attempt)
+		if err != nil {
+			return err // Stopped by ctx
+		}
+		supervisor.locker.Lock()
+		supervisor.lastCrash = crash
+		supervisor.locker.Unlock()
+		supervisor.emit(Event{Type: EventExited, Attempt: attempt, Crash: crash})
+
+		if supervisor.Policy == Never || (supervisor.Policy == OnFailure && !crash.Failed()) {
+			return crash.Err
+		}
+
+		// Count failures in window
+		delay := backoff
+		if crash.Failed() {
+			failures = append(failures, crash.Time)
+			for len(failures) > 0 && crash.Time.Sub(failures[0]) > loopWindow {
+				failures = failures[1:]
+			}
+			if len(failures) >= loopCount {
+				supervisor.emit(Event{Type: EventCrashLoop, Attempt: attempt, Crash: crash})
+				return fmt.Errorf("%w: %d failures in %s", ErrCrashLoop, len(failures), loopWindow)
+			}
+			for range len(failures) - 1 {
+				if delay *= 2; delay >= maxBackoff {
+					break
+				}
+			}
+		}
+		delay = min(delay, maxBackoff)
+
+		supervisor.emit(Event{Type: EventRestarting, Attempt: attempt, Delay: delay})
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			supervisor.emit(Event{Type: EventStopped, Attempt: attempt})
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Start process and wait exit, return error only if stopped by ctx
+func (supervisor *Supervisor) run(ctx context.Context, attempt int) (*Crash, error) {
+	output := &runLog{max: supervisor.LogLines}
+	if output.max <= 0 {
+		output.max = DefaultLogLines
+	}
+	if supervisor.Parse != nil {
+		output.parse = supervisor.Parse()
+	}
+	stdout, stderr := &lineWriter{log: output}, &lineWriter{log: output}
+	supervisor.Proc.AppendToStdout(stdout)
+	supervisor.Proc.AppendToStderr(stderr)
+	defer stdout.Close()
+	defer stderr.Close()
+
+	started := time.Now()
+	if err := supervisor.Proc.Start(supervisor.Options); err != nil {
+		return &Crash{ExitCode: -1, Time: time.Now(), Lines: []string{}, Err: err}, nil
+	}
+	supervisor.emit(Event{Type: EventStarted, Attempt: attempt})
+
+	exited := make(chan struct{})
+	go func() {
+		supervisor.Proc.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		status, err := server.Stop(context.WithoutCancel(ctx), supervisor.Proc, supervisor.StopOptions)
+		event := Event{Type: EventStopped, Attempt: attempt}
+		if status != nil {
+			event.Crash = &Crash{ExitCode: status.ExitCode, Time: time.Now(), Uptime: time.Since(started), Lines: []string{}}
+		}
+		supervisor.emit(event)
+		return nil, errors.Join(ctx.Err(), err)
+	}
+
+	code, _ := supervisor.Proc.ExitCode()
+	stdout.Close()
+	stderr.Close()
+	crash := &Crash{ExitCode: code, Time: time.Now(), Uptime: time.Since(started)}
+	crash.Lines, crash.Reason = output.result()
+	return crash, nil
+}
+
+func (supervisor *Supervisor) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if supervisor.OnEvent != nil {
+		supervisor.OnEvent(event)
+	}
+}
+
+// Last lines and error of process run
+type runLog struct {
+	locker sync.Mutex
+	max    int
+	lines  []string
+	parse  logs.StreamParse
+	reason *logs.ErrorReference
+}
+
+func (output *runLog) add(line string) {
+	output.locker.Lock()
+	defer output.locker.Unlock()
+	if output.lines = append(output.lines, line); len(output.lines) > output.max {
+		output.lines = output.lines[len(output.lines)-output.max:]
+	}
+	if output.parse != nil {
+		output.events(output.parse.Next(line))
+	}
+}
+
+func (output *runLog) events(events []logs.Event) {
+	for _, event := range events {
+		if event.Err != nil {
+			output.reason = event.Err
+		}
+	}
+}
+
+func (output *runLog) result() ([]string, *logs.ErrorReference) {
+	output.locker.Lock()
+	defer output.locker.Unlock()
+	if output.parse != nil {
+		output.events(output.parse.Flush())
+		output.parse = nil
+	}
+	return append([]string{}, output.lines...), output.reason
+}
+
+// Split output in lines to runLog, after Close return [io.ErrClosedPipe]
+// to be removed from process writers
+type lineWriter struct {
+	locker  sync.Mutex
+	log     *runLog
+	partial string
+	closed  bool
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+	w.partial += string(p)
+	for {
+		line, rest, ok := strings.Cut(w.partial, "\n")
+		if !ok {
+			break
+		}
+		w.log.add(strings.TrimSuffix(line, "\r"))
+		w.partial = rest
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) Close() error {
+	w.locker.Lock()
+	defer w.locker.Unlock()
+	if !w.closed && w.partial != "" {
+		w.log.add(strings.TrimSuffix(w.partial, "\r"))
+	}
+	w.closed, w.partial = true, ""
+	return nil
+}
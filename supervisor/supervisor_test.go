@@ -0,0 +1,75 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	bdsexec "sirherobrine23.com.br/go-bds/go-bds/exec"
+	"sirherobrine23.com.br/go-bds/go-bds/logs"
+	javalog "sirherobrine23.com.br/go-bds/go-bds/logs/java"
+	"sirherobrine23.com.br/go-bds/go-bds/server"
+)
+
+func TestSupervisor(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+
+	// Crash loop with error reason from log
+	var events []EventType
+	crashing := &Supervisor{
+		Proc:            &bdsexec.Os{},
+		Options:         bdsexec.ProcExec{Arguments: []string{"sh", "-c", "echo '[21:41:40] [Server thread/INFO]: Starting minecraft server version 1.21.1'; echo '[21:41:40] [Server thread/ERROR]: Encountered an unexpected exception'; echo 'java.lang.NullPointerException'; exit 3"}},
+		Policy:          OnFailure,
+		Backoff:         10 * time.Millisecond,
+		CrashLoopCount:  3,
+		CrashLoopWindow: time.Minute,
+		LogLines:        2,
+		Parse:           func() logs.StreamParse { return &javalog.JavaParse{} },
+		OnEvent:         func(event Event) { events = append(events, event.Type) },
+	}
+	if err := crashing.Run(context.Background()); !errors.Is(err, ErrCrashLoop) {
+		t.Fatalf("expected crash loop, got %v", err)
+	}
+	expected := []EventType{EventStarted, EventExited, EventRestarting, EventStarted, EventExited, EventRestarting, EventStarted, EventExited, EventCrashLoop}
+	if !slices.Equal(events, expected) {
+		t.Errorf("invalid events: %v", events)
+	}
+	crash := crashing.LastCrash()
+	if crash == nil || crash.ExitCode != 3 || len(crash.Lines) != 2 || crash.Lines[1] != "java.lang.NullPointerException" {
+		t.Errorf("invalid crash: %+v", crash)
+	} else if crash.Reason == nil || !strings.Contains(crash.Reason.FistLine, "Encountered an unexpected exception") {
+		t.Errorf("crash reason not captured: %+v", crash.Reason)
+	}
+
+	// Clean exit with on-failure not restart
+	events = nil
+	crashing.Options.Arguments = []string{"sh", "-c", "exit 0"}
+	if err := crashing.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if !slices.Equal(events, []EventType{EventStarted, EventExited}) {
+		t.Errorf("invalid events: %v", events)
+	}
+
+	// Stop by ctx
+	events = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	running := &Supervisor{
+		Proc:        &bdsexec.Os{},
+		Options:     bdsexec.ProcExec{Arguments: []string{"sleep", "10"}},
+		Policy:      Always,
+		StopOptions: server.StopOptions{Grace: 10 * time.Millisecond, SignalGrace: time.Second},
+		OnEvent:     func(event Event) { events = append(events, event.Type) },
+	}
+	if err := running.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ctx error, got %v", err)
+	} else if !slices.Equal(events, []EventType{EventStarted, EventStopped}) {
+		t.Errorf("invalid events: %v", events)
+	}
+}
@@ -0,0 +1,276 @@
+// Extract tar and zip archives without files escaping target folder
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrPath error = errors.New("invalid path in archive") // Absolute path, ".." or symlink escaping target
+
+// Clean archive path, check if is inside target and remove strip first folders
+func archivePath(name string, strip int) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "./"))
+	if clean == "." {
+		return "", nil
+	} else if !fs.ValidPath(clean) || filepath.IsAbs(name) || strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %q", ErrPath, name)
+	}
+	for ; strip > 0; strip-- {
+		_, after, ok := strings.Cut(clean, "/")
+		if !ok {
+			return "", nil
+		}
+		clean = after
+	}
+	return clean, nil
+}
+
+// Check symlink target is inside archive
+func checkLink(name, link string) error {
+	if filepath.IsAbs(link) || strings.HasPrefix(link, "/") || !fs.ValidPath(path.Clean(path.Join(path.Dir(name), link))) {
+		return fmt.Errorf("%w: %q link to %q", ErrPath, name, link)
+	}
+	return nil
+}
+
+// Write archive entries with paths resolved by [os.Root], so chain of symlinks
+// cannot write files outside target
+type extractor struct {
+	root   *os.Root
+	target string
+	links  []string // Symlinks checked after extract
+}
+
+func newExtractor(target string) (*extractor, error) {
+	if err := os.MkdirAll(target, 0755); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(target)
+	if err != nil {
+		return nil, err
+	}
+	return &extractor{root: root, target: target}, nil
+}
+
+// Create folder and parents, existing folders and symlinks must resolve to folder inside target
+func (ext *extractor) mkdirAll(name string, perm fs.FileMode) error {
+	if name == "." || name == "" {
+		return nil
+	}
+	current := ""
+	for part := range strings.SplitSeq(name, "/") {
+		current = path.Join(current, part)
+		if err := ext.root.Mkdir(current, perm); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: %q: %w", ErrPath, name, err)
+		}
+	}
+	if info, err := ext.root.Stat(name); err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrPath, name, err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("%w: %q not is folder", ErrPath, name)
+	}
+	return nil
+}
+
+func (ext *extractor) writeFile(name string, mode fs.FileMode, r io.Reader) error {
+	if err := ext.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	fileOpen, err := ext.root.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode.Perm()|0600)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrPath, name, err)
+	}
+	defer fileOpen.Close()
+	if _, err = io.Copy(fileOpen, r); err != nil {
+		return err
+	}
+	return fileOpen.Close()
+}
+
+func (ext *extractor) symlink(name, link string) error {
+	if err := checkLink(name, link); err != nil {
+		return err
+	} else if err = ext.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	}
+	ext.links = append(ext.links, name)
+	return os.Symlink(link, filepath.Join(ext.target, filepath.FromSlash(name)))
+}
+
+// Hardlink name to linkName, linkName and folders of both paths cannot be symlinks because
+// link is created by real path and [os.Link] follow symlinks in folders
+func (ext *extractor) link(name, linkName string) error {
+	if err := ext.mkdirAll(path.Dir(name), 0755); err != nil {
+		return err
+	} else if err = ext.noSymlink(path.Dir(name)); err != nil {
+		return err
+	} else if err = ext.noSymlink(linkName); err != nil {
+		return err
+	} else if info, err := ext.root.Lstat(linkName); err != nil {
+		return fmt.Errorf("%w: %q link to %q: %w", ErrPath, name, linkName, err)
+	} else if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %q link to %q not is file", ErrPath, name, linkName)
+	}
+	ext.root.Remove(name)
+	return os.Link(filepath.Join(ext.target, filepath.FromSlash(linkName)), filepath.Join(ext.target, filepath.FromSlash(name)))
+}
+
+// Return error if any folder in name or name is symlink
+func (ext *extractor) noSymlink(name string) error {
+	if name == "." || name == "" {
+		return nil
+	}
+	current := ""
+	for part := range strings.SplitSeq(name, "/") {
+		current = path.Join(current, part)
+		if info, err := ext.root.Lstat(current); err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %q resolve by symlink %q", ErrPath, name, current)
+		}
+	}
+	return nil
+}
+
+// Check all symlinks resolve inside target, links to files not in archive are accepted
+func (ext *extractor) checkLinks() error {
+	for _, name := range ext.links {
+		if _, err := ext.root.Stat(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w: %q: %w", ErrPath, name, err)
+		}
+	}
+	return nil
+}
+
+// Extract tar to target, gzip compressed tar is detected. Strip remove first folders from
+// names like "tar --strip-components", return files and folders extracted slash separated
+func Tar(r io.Reader, target string, strip int) ([]string, error) {
+	buffered := bufio.NewReader(r)
+	if magic, _ := buffered.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	ext, err := newExtractor(target)
+	if err != nil {
+		return nil, err
+	}
+	defer ext.root.Close()
+
+	files, tarball := []string{}, tar.NewReader(r)
+	for {
+		header, err := tarball.Next()
+		if err == io.EOF {
+			return files, ext.checkLinks()
+		} else if err != nil {
+			return nil, err
+		}
+
+		name, err := archivePath(header.Name, strip)
+		if err != nil {
+			return nil, err
+		} else if name == "" {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = ext.mkdirAll(name, header.FileInfo().Mode().Perm()|0700)
+		case tar.TypeReg:
+			err = ext.writeFile(name, header.FileInfo().Mode(), tarball)
+		case tar.TypeSymlink:
+			err = ext.symlink(name, header.Linkname)
+		case tar.TypeLink:
+			var linkName string
+			if linkName, err = archivePath(header.Linkname, strip); err == nil && linkName != "" {
+				err = ext.link(name, linkName)
+			}
+		default:
+			continue // Devices and fifos not exists in servers and rootfs
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, name)
+	}
+}
+
+// Extract zip to target, if r not is [io.ReaderAt] and [io.Seeker] is copied to temporary file.
+// Strip remove first folders from names, return files and folders extracted slash separated
+func Zip(r io.Reader, target string, strip int) ([]string, error) {
+	// Zip require ReaderAt, copy to temporary file if needed
+	readerAt, ok := r.(io.ReaderAt)
+	var size int64
+	if seeker, isSeeker := r.(io.Seeker); ok && isSeeker {
+		var err error
+		if size, err = seeker.Seek(0, io.SeekEnd); err != nil {
+			return nil, err
+		}
+	} else {
+		tmp, err := os.CreateTemp("", "go-bds-archive-*.zip")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if size, err = io.Copy(tmp, r); err != nil {
+			return nil, err
+		}
+		readerAt = tmp
+	}
+
+	zipFile, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+
+	ext, err := newExtractor(target)
+	if err != nil {
+		return nil, err
+	}
+	defer ext.root.Close()
+
+	files := []string{}
+	for _, file := range zipFile.File {
+		name, err := archivePath(file.Name, strip)
+		if err != nil {
+			return nil, err
+		} else if name == "" {
+			continue
+		}
+
+		switch mode := file.Mode(); {
+		case mode.IsDir():
+			err = ext.mkdirAll(name, mode.Perm()|0700)
+		case mode.IsRegular():
+			var fileOpen io.ReadCloser
+			if fileOpen, err = file.Open(); err == nil {
+				err = ext.writeFile(name, mode, fileOpen)
+				fileOpen.Close()
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, name)
+	}
+	return files, ext.checkLinks()
+}
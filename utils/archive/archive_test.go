@@ -0,0 +1,44 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTarHardlink(t *testing.T) {
+	parent := t.TempDir()
+	if err := os.WriteFile(filepath.Join(parent, "secret.txt"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(parent, "target")
+
+	// Hardlink to file in archive
+	var buff bytes.Buffer
+	tarball := tar.NewWriter(&buff)
+	tarball.WriteHeader(&tar.Header{Name: "server.jar", Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+	tarball.Write([]byte("java"))
+	tarball.WriteHeader(&tar.Header{Name: "libs/server.jar", Linkname: "server.jar", Typeflag: tar.TypeLink})
+	tarball.Close()
+	if _, err := Tar(&buff, target, 0); err != nil {
+		t.Fatal(err)
+	} else if data, _ := os.ReadFile(filepath.Join(target, "libs/server.jar")); string(data) != "java" {
+		t.Errorf("invalid hardlink content: %q", data)
+	}
+
+	// Hardlink by symlink chain to file outside target
+	buff.Reset()
+	tarball = tar.NewWriter(&buff)
+	tarball.WriteHeader(&tar.Header{Name: "a", Linkname: ".", Typeflag: tar.TypeSymlink})
+	tarball.WriteHeader(&tar.Header{Name: "b", Linkname: "a/..", Typeflag: tar.TypeSymlink})
+	tarball.WriteHeader(&tar.Header{Name: "stolen.txt", Linkname: "b/secret.txt", Typeflag: tar.TypeLink})
+	tarball.Close()
+	if _, err := Tar(&buff, target, 0); !errors.Is(err, ErrPath) {
+		t.Errorf("hardlink by symlink not detected: %v", err)
+	} else if _, err = os.Lstat(filepath.Join(target, "stolen.txt")); !os.IsNotExist(err) {
+		t.Errorf("hardlink created: %v", err)
+	}
+}
@@ -0,0 +1,443 @@
+// Content-addressed download cache shared by installers,
+// files are saved by expected hash or by url and reused in next installs
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/go-bds/utils/archive"
+	"sirherobrine23.com.br/go-bds/request/v2"
+)
+
+var (
+	ErrHash      error = errors.New("file hash not match")          // Downloaded or seed file with other hash
+	ErrAlgorithm error = errors.New("hash algorithm not supported") // Hash algorithm not is sha1, sha256 or sha512
+	ErrStatus    error = errors.New("invalid http status")          // Server response without 2xx status
+)
+
+// Hash algorithms
+const (
+	SHA1   = "sha1"
+	SHA256 = "sha256"
+	SHA512 = "sha512"
+)
+
+// Cache used by installers, nil download files without cache
+var Default *Cache
+
+// Expected file hash, empty Sum skip file check
+type Hash struct {
+	Algorithm string `json:"algorithm"` // sha1, sha256 or sha512
+	Sum       string `json:"sum"`       // Hex encoded hash
+}
+
+// New hash to algorithm
+func (h Hash) New() (hash.Hash, error) {
+	switch strings.ToLower(h.Algorithm) {
+	case SHA1:
+		return sha1.New(), nil
+	case SHA256:
+		return sha256.New(), nil
+	case SHA512:
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrAlgorithm, h.Algorithm)
+}
+
+// Check hex sum from hash
+func (h Hash) Check(sum hash.Hash) error {
+	if got := hex.EncodeToString(sum.Sum(nil)); !strings.EqualFold(got, h.Sum) {
+		return fmt.Errorf("%w: expected %s %s, got %s", ErrHash, h.Algorithm, h.Sum, got)
+	}
+	return nil
+}
+
+// Read all r and check hash, empty Sum skip check
+func (h Hash) Verify(r io.Reader) error {
+	if h.Sum == "" {
+		return nil
+	}
+	sum, err := h.New()
+	if err != nil {
+		return err
+	} else if _, err = io.Copy(sum, r); err != nil {
+		return err
+	}
+	return h.Check(sum)
+}
+
+// File name in cache, "<algorithm>-<sum>" with hash or "url-<sha256 of url>"
+func (h Hash) key(fileURL string) string {
+	if h.Sum == "" {
+		sum := sha256.Sum256([]byte(fileURL))
+		return "url-" + hex.EncodeToString(sum[:])
+	}
+	return strings.ToLower(h.Algorithm + "-" + h.Sum)
+}
+
+// Files downloaded saved in Folder, least recently used files are removed when cache size is more than MaxSize
+type Cache struct {
+	Folder  string       // Folder to save downloaded files
+	Seed    []string     // Read only folders with files named like cache or with url file name if hash is known, checked before download
+	MaxSize int64        // Cache size limit in bytes, 0 is unlimited
+	Client  *http.Client // HTTP client, default is [http.DefaultClient]
+
+	locker sync.Mutex          // Lock keys and evict
+	keys   map[string]*keyLock // Keys opening or downloading, other keys are downloaded in parallel
+}
+
+type keyLock struct {
+	sync.Mutex
+	users int
+}
+
+// Lock only key, return function to unlock
+func (cache *Cache) lock(key string) func() {
+	cache.locker.Lock()
+	if cache.keys == nil {
+		cache.keys = map[string]*keyLock{}
+	}
+	lock, ok := cache.keys[key]
+	if !ok {
+		lock = &keyLock{}
+		cache.keys[key] = lock
+	}
+	lock.users++
+	cache.locker.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		cache.locker.Lock()
+		defer cache.locker.Unlock()
+		if lock.users--; lock.users == 0 {
+			delete(cache.keys, key)
+		}
+	}
+}
+
+// File opened from cache or downloaded
+type File struct {
+	*os.File
+	Size int64 // File size
+
+	temporary bool
+}
+
+// Close file and remove if is temporary download
+func (file *File) Close() error {
+	err := file.File.Close()
+	if file.temporary {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// Open file from cache or seed folders and download if not exists.
+// nil Cache download to temporary file removed in [*File.Close], progress can be nil
+func (cache *Cache) Open(ctx context.Context, fileURL string, expected Hash, options *request.Options, progress Progress) (*File, error) {
+	if expected.Sum != "" {
+		if _, err := expected.New(); err != nil {
+			return nil, err
+		}
+	}
+	if cache == nil {
+		return download(ctx, http.DefaultClient, fileURL, expected, options, "", progress)
+	}
+
+	key := expected.key(fileURL)
+	defer cache.lock(key)()
+	cached := filepath.Join(cache.Folder, key)
+	if file, err := openFile(cached, expected, progress); err == nil {
+		now := time.Now()
+		os.Chtimes(cached, now, now) // Last use to LRU
+		return file, nil
+	} else if errors.Is(err, ErrHash) {
+		os.Remove(cached) // Corrupted file, download again
+	}
+
+	// Seed files, by key or url file name if hash is known, without hash same
+	// file name can be other version like "server.jar"
+	for _, seed := range cache.Seed {
+		names := []string{key}
+		if parsed, err := url.Parse(fileURL); err == nil && expected.Sum != "" && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+			names = append(names, path.Base(parsed.Path))
+		}
+		for _, name := range names {
+			if file, err := openFile(filepath.Join(seed, name), expected, progress); err == nil {
+				return file, nil
+			}
+		}
+	}
+
+	if err := os.MkdirAll(cache.Folder, 0755); err != nil {
+		return nil, err
+	}
+	client := cache.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	file, err := download(ctx, client, fileURL, expected, options, cache.Folder, progress)
+	if err != nil {
+		return nil, err
+	}
+	file.File.Close() // Keep file to rename
+	if err = os.Rename(file.Name(), cached); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+	cache.locker.Lock()
+	err = cache.evict(key)
+	cache.locker.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return openFile(cached, Hash{}, nil)
+}
+
+// Download url and hash resolved by upstream API, example latest JDK release
+type Resolved struct {
+	URL  string `json:"url"`
+	Hash Hash   `json:"hash"`
+}
+
+// Call resolve and save result to "resolved/<name>.json" in Folder, if resolve fail last
+// result saved in Folder or Seed folders is returned, so hosts without upstream access
+// can install files seeded with resolved file
+func (cache *Cache) Resolve(name string, resolve func() (Resolved, error)) (Resolved, error) {
+	resolved, err := resolve()
+	if cache == nil {
+		return resolved, err
+	} else if err == nil {
+		data, err := json.Marshal(resolved)
+		if err != nil {
+			return resolved, err
+		} else if err = os.MkdirAll(filepath.Join(cache.Folder, "resolved"), 0755); err != nil {
+			return resolved, err
+		}
+		return resolved, os.WriteFile(filepath.Join(cache.Folder, "resolved", name+".json"), data, 0644)
+	}
+
+	for _, folder := range append([]string{cache.Folder}, cache.Seed...) {
+		var saved Resolved
+		if data, readErr := os.ReadFile(filepath.Join(folder, "resolved", name+".json")); readErr != nil {
+			continue
+		} else if json.Unmarshal(data, &saved) == nil && saved.URL != "" {
+			return saved, nil
+		}
+	}
+	return resolved, err
+}
+
+// Copy file to path, parent folders are created. File is written with temporary name
+// and renamed after copy, so canceled copy not leave half-written file
+func (cache *Cache) SaveAs(ctx context.Context, fileURL string, expected Hash, filePath string, options *request.Options, progress Progress) error {
+	file, err := cache.Open(ctx, fileURL, expected, options, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	target, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(target.Name())
+	defer target.Close()
+	if _, err = io.Copy(target, &progressReader{ctx: ctx, file: file}); err != nil {
+		return err
+	} else if err = target.Chmod(0644); err != nil {
+		return err
+	} else if err = target.Close(); err != nil {
+		return err
+	}
+	return os.Rename(target.Name(), filePath)
+}
+
+// Extract tar or gzip compressed tar to extract.Cwd with [InstallFolder]
+func (cache *Cache) Tar(ctx context.Context, fileURL string, expected Hash, extract request.ExtractOptions, options *request.Options, progress Progress) error {
+	file, err := cache.Open(ctx, fileURL, expected, options, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return InstallFolder(extract.Cwd, func(staging string) error {
+		_, err := archive.Tar(&progressReader{ctx: ctx, file: file, phase: PhaseExtract, progress: progress}, staging, extract.Strip)
+		return err
+	})
+}
+
+// Extract zip to extract.Cwd with [InstallFolder]
+func (cache *Cache) Zip(ctx context.Context, fileURL string, expected Hash, extract request.ExtractOptions, options *request.Options, progress Progress) error {
+	file, err := cache.Open(ctx, fileURL, expected, options, progress)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return InstallFolder(extract.Cwd, func(staging string) error {
+		_, err := archive.Zip(&progressReader{ctx: ctx, file: file, phase: PhaseExtract, progress: progress}, staging, extract.Strip)
+		return err
+	})
+}
+
+// Current cache size in bytes
+func (cache *Cache) Size() (int64, error) {
+	files, err := cache.files()
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+	return size, nil
+}
+
+// Cached files, temporary downloads are ignored
+func (cache *Cache) files() ([]fs.FileInfo, error) {
+	entries, err := os.ReadDir(cache.Folder)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	files := []fs.FileInfo{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
+
+// Remove least recently used files until cache fit in MaxSize, keep file is never removed
+func (cache *Cache) evict(keep string) error {
+	if cache.MaxSize <= 0 {
+		return nil
+	}
+	files, err := cache.files()
+	if err != nil {
+		return err
+	}
+	var size int64
+	for _, file := range files {
+		size += file.Size()
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int { return a.ModTime().Compare(b.ModTime()) })
+	for _, file := range files {
+		if size <= cache.MaxSize {
+			break
+		} else if file.Name() == keep {
+			continue
+		} else if err := os.Remove(filepath.Join(cache.Folder, file.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= file.Size()
+	}
+	return nil
+}
+
+// Open file and check hash if Sum not empty
+func openFile(filePath string, expected Hash, progress Progress) (*File, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	} else if !stat.Mode().IsRegular() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+
+	if expected.Sum != "" {
+		verify := &progressReader{ctx: context.Background(), file: &File{File: file, Size: stat.Size()}, phase: PhaseVerify, progress: progress}
+		if err = expected.Verify(verify); err == nil {
+			_, err = file.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &File{File: file, Size: stat.Size()}, nil
+}
+
+// Download to temporary file in folder, empty folder use system temporary folder
+func download(ctx context.Context, client *http.Client, fileURL string, expected Hash, options *request.Options, folder string, progress Progress) (*File, error) {
+	method := http.MethodGet
+	if options != nil && options.Method != "" {
+		method = options.Method
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fileURL, nil)
+	if err != nil {
+		return nil, err
+	} else if options != nil {
+		for key, value := range options.Header {
+			req.Header.Set(key, value)
+		}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s return %s", ErrStatus, fileURL, res.Status)
+	}
+
+	tmp, err := os.CreateTemp(folder, ".download-*")
+	if err != nil {
+		return nil, err
+	}
+	file := &File{File: tmp, temporary: true}
+
+	var w io.Writer = &progressWriter{w: tmp, total: res.ContentLength, phase: PhaseDownload, progress: progress}
+	var sum hash.Hash
+	if expected.Sum != "" {
+		sum, _ = expected.New()
+		w = io.MultiWriter(w, sum)
+	}
+	if file.Size, err = io.Copy(w, res.Body); err == nil && sum != nil {
+		if err = expected.Check(sum); err == nil && progress != nil {
+			progress(PhaseVerify, file.Size, file.Size) // Hash made while downloading
+		}
+	}
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Install step reported to [Progress]
+type Phase string
+
+const (
+	PhaseDownload Phase = "download" // Downloading file, total is -1 if server not send size
+	PhaseVerify   Phase = "verify"   // Checking file hash
+	PhaseExtract  Phase = "extract"  // Extracting archive, bytes read from archive file
+)
+
+// Receive install progress, done and total in bytes
+type Progress func(phase Phase, done, total int64)
+
+// Count bytes written to download file
+type progressWriter struct {
+	w           io.Writer
+	done, total int64
+	phase       Phase
+	progress    Progress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if w.done += int64(n); w.progress != nil {
+		w.progress(w.phase, w.done, w.total)
+	}
+	return n, err
+}
+
+// Count bytes read from file and stop reading if context is canceled,
+// implements [io.ReaderAt] and [io.Seeker] to zip
+type progressReader struct {
+	ctx      context.Context
+	file     *File
+	done     int64
+	phase    Phase
+	progress Progress
+}
+
+func (r *progressReader) count(n int) {
+	if r.done += int64(n); r.progress != nil && n > 0 {
+		r.progress(r.phase, min(r.done, r.file.Size), r.file.Size)
+	}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.file.Read(p)
+	r.count(n)
+	return n, err
+}
+
+func (r *progressReader) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.file.ReadAt(p, off)
+	r.count(n)
+	return n, err
+}
+
+func (r *progressReader) Seek(offset int64, whence int) (int64, error) {
+	return r.file.Seek(offset, whence)
+}
+
+// Run install in temporary folder next to target and move files to target on success.
+// Target is not changed if install fail or context is canceled, files with same name in target are replaced
+func InstallFolder(target string, install func(staging string) error) error {
+	target = filepath.Clean(target)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(target), "."+filepath.Base(target)+"-install-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	if err = install(staging); err != nil {
+		return err
+	} else if err = os.Chmod(staging, 0755); err != nil {
+		return err
+	}
+
+	// Empty or not exists target is replaced by staging folder
+	if entries, err := os.ReadDir(target); os.IsNotExist(err) || (err == nil && len(entries) == 0) {
+		os.Remove(target)
+		return os.Rename(staging, target)
+	} else if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(staging)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err = os.RemoveAll(filepath.Join(target, entry.Name())); err != nil {
+			return err
+		} else if err = os.Rename(filepath.Join(staging, entry.Name()), filepath.Join(target, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package cache
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sirherobrine23.com.br/go-bds/request/v2"
+)
+
+func TestCache(t *testing.T) {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	tw.WriteHeader(&tar.Header{Name: "jdk-21/bin/java", Mode: 0755, Size: 4, Typeflag: tar.TypeReg})
+	tw.Write([]byte("java"))
+	tw.Close()
+
+	files := map[string][]byte{
+		"/server.jar": []byte("server jar content"),
+		"/other.jar":  []byte("other jar content"),
+		"/plugin.jar": []byte("plugin jar content"),
+		"/jdk.tar":    tarball.Bytes(),
+	}
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if data, ok := files[r.URL.Path]; ok {
+			w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer upstream.Close()
+
+	sum := sha256.Sum256(files["/server.jar"])
+	serverHash := Hash{Algorithm: SHA256, Sum: hex.EncodeToString(sum[:])}
+	cache := &Cache{Folder: t.TempDir(), Client: upstream.Client()}
+	read := func(url string, hash Hash) (string, error) {
+		file, err := cache.Open(context.Background(), url, hash, nil, nil)
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		return string(data), err
+	}
+
+	// Download once and reuse by hash
+	for range 2 {
+		if data, err := read(upstream.URL+"/server.jar", serverHash); err != nil {
+			t.Fatal(err)
+		} else if data != "server jar content" {
+			t.Errorf("invalid content: %q", data)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected one request, got %d", requests.Load())
+	} else if _, err := os.Stat(filepath.Join(cache.Folder, "sha256-"+serverHash.Sum)); err != nil {
+		t.Errorf("file not saved by hash: %s", err)
+	}
+
+	// Corrupted cache file is downloaded again
+	os.WriteFile(filepath.Join(cache.Folder, "sha256-"+serverHash.Sum), []byte("corrupted"), 0644)
+	if data, err := read(upstream.URL+"/server.jar", serverHash); err != nil || data != "server jar content" {
+		t.Errorf("corrupted file not replaced: %q, %v", data, err)
+	} else if requests.Load() != 2 {
+		t.Errorf("expected new request, got %d", requests.Load())
+	}
+
+	// Invalid hash is not cached
+	if _, err := read(upstream.URL+"/other.jar", Hash{Algorithm: SHA1, Sum: "00"}); !errors.Is(err, ErrHash) {
+		t.Errorf("expected hash error, got %v", err)
+	} else if _, err = read(upstream.URL+"/missing.jar", Hash{}); !errors.Is(err, ErrStatus) {
+		t.Errorf("expected status error, got %v", err)
+	} else if size, _ := cache.Size(); size != int64(len(files["/server.jar"])) {
+		t.Errorf("invalid files in cache, size %d", size)
+	}
+
+	// Pre-seeded folder by url file name with hash, no request
+	cache.Seed = []string{t.TempDir()}
+	os.WriteFile(filepath.Join(cache.Seed[0], "seed.jar"), []byte("from seed"), 0644)
+	seedSum := sha256.Sum256([]byte("from seed"))
+	requests.Store(0)
+	if data, err := read(upstream.URL+"/seed.jar", Hash{Algorithm: SHA256, Sum: hex.EncodeToString(seedSum[:])}); err != nil || data != "from seed" {
+		t.Errorf("seed file not used: %q, %v", data, err)
+	} else if requests.Load() != 0 {
+		t.Errorf("request made to seed file")
+	}
+
+	// Without hash url file name can be other version
+	os.WriteFile(filepath.Join(cache.Seed[0], "plugin.jar"), []byte("old plugin jar"), 0644)
+	if data, err := read(upstream.URL+"/plugin.jar", Hash{}); err != nil || data != "plugin jar content" {
+		t.Errorf("seed file used without hash: %q, %v", data, err)
+	} else if requests.Load() != 1 {
+		t.Errorf("expected request without hash, got %d", requests.Load())
+	}
+
+	// Extract with strip and progress
+	target := filepath.Join(t.TempDir(), "jdk")
+	phases := map[Phase]int64{}
+	progress := func(phase Phase, done, total int64) { phases[phase] = done }
+	if err := cache.Tar(context.Background(), upstream.URL+"/jdk.tar", Hash{}, request.ExtractOptions{Cwd: target, Strip: 1}, nil, progress); err != nil {
+		t.Fatal(err)
+	} else if data, _ := os.ReadFile(filepath.Join(target, "bin/java")); string(data) != "java" {
+		t.Errorf("invalid extracted file: %q", data)
+	} else if phases[PhaseDownload] != int64(len(files["/jdk.tar"])) || phases[PhaseExtract] == 0 {
+		t.Errorf("invalid progress: %v", phases)
+	}
+
+	// Canceled extract keep target unchanged
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled := filepath.Join(t.TempDir(), "canceled")
+	if err := cache.Tar(ctx, upstream.URL+"/jdk.tar", Hash{}, request.ExtractOptions{Cwd: canceled, Strip: 1}, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled error, got %v", err)
+	} else if entries, _ := os.ReadDir(filepath.Dir(canceled)); len(entries) != 0 {
+		t.Errorf("canceled install left files: %v", entries)
+	}
+
+	// Least recently used file removed
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(cache.Folder, "sha256-"+serverHash.Sum), old, old)
+	cache.MaxSize = int64(len(files["/other.jar"]) + len(files["/jdk.tar"]))
+	if _, err := read(upstream.URL+"/other.jar", Hash{}); err != nil {
+		t.Fatal(err)
+	} else if _, err = os.Stat(filepath.Join(cache.Folder, "sha256-"+serverHash.Sum)); !os.IsNotExist(err) {
+		t.Errorf("least recently used file not removed: %v", err)
+	} else if size, _ := cache.Size(); size > cache.MaxSize {
+		t.Errorf("cache size %d more than limit %d", size, cache.MaxSize)
+	}
+}
+
+func TestCacheParallel(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow.jar" {
+			close(started)
+			<-release
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	cache, slowDone := &Cache{Folder: t.TempDir(), Client: upstream.Client()}, make(chan struct{})
+	defer func() { close(release); <-slowDone }()
+	go func() {
+		defer close(slowDone)
+		if file, err := cache.Open(context.Background(), upstream.URL+"/slow.jar", Hash{}, nil, nil); err == nil {
+			file.Close()
+		}
+	}()
+	<-started
+
+	// Other key not wait slow download
+	done := make(chan error)
+	go func() {
+		file, err := cache.Open(context.Background(), upstream.URL+"/fast.jar", Hash{}, nil, nil)
+		if err == nil {
+			file.Close()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("download locked by other key")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cache, upstream := &Cache{Folder: t.TempDir()}, errors.New("upstream down")
+	resolved := Resolved{URL: "https://example.com/jdk-21.tar.gz", Hash: Hash{Algorithm: SHA256, Sum: "00"}}
+	if _, err := cache.Resolve("jdk-21", func() (Resolved, error) { return Resolved{}, upstream }); err != upstream {
+		t.Errorf("expected upstream error, got %v", err)
+	} else if got, err := cache.Resolve("jdk-21", func() (Resolved, error) { return resolved, nil }); err != nil || got != resolved {
+		t.Errorf("invalid resolved: %+v, %v", got, err)
+	}
+
+	// Seeded host without upstream
+	seeded := &Cache{Folder: t.TempDir(), Seed: []string{cache.Folder}}
+	if got, err := seeded.Resolve("jdk-21", func() (Resolved, error) { return Resolved{}, upstream }); err != nil || got != resolved {
+		t.Errorf("saved resolved not used: %+v, %v", got, err)
+	}
+}
@@ -1,34 +1,26 @@
 package javaprebuild
 
 import (
+	"context"
 	"fmt"
-	"net/http"
-	"path"
-	"path/filepath"
 	"runtime"
-	"slices"
-	"strings"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/request/v2"
-	"sirherobrine23.com.br/sirherobrine23/go-dpkg/dpkg"
 )
 
-type adoptiumReleases struct {
-	MostRecentRelease uint   `json:"most_recent_feature_release"`
-	MostRecentLts     uint   `json:"most_recent_lts"`
-	MostRecent        uint   `json:"most_recent_feature_version"`
-	TIP               uint   `json:"tip_version"`
-	LTSRelease        []uint `json:"available_lts_releases"`
-	Release           []uint `json:"available_releases"`
+type adoptiumAsset struct {
+	Binary struct {
+		Package struct {
+			Link     string `json:"link"`
+			Checksum string `json:"checksum"`
+		} `json:"package"`
+	} `json:"binary"`
 }
 
 // Install latest version from adoptium
-func (ver JavaVersion) InstallLatestAdoptium(installPath string) error {
+func (ver JavaVersion) InstallLatestAdoptium(ctx context.Context, installPath string, progress cache.Progress) error {
 	featVersion := uint(ver) - 44
-	releases, _, _ := request.JSON[adoptiumReleases]("https://api.adoptium.net/v3/info/available_releases", nil)
-	if !slices.Contains(releases.Release, featVersion) {
-		return ErrSystem
-	}
 
 	// architecture: x64, x86, x32, ppc64, ppc64le, s390x, aarch64, arm, sparcv9, riscv64
 	arch := runtime.GOARCH
@@ -50,47 +42,18 @@ func (ver JavaVersion) InstallLatestAdoptium(installPath string) error {
 		goos = "solaris"
 	}
 
-	opt := &request.Options{
-		NotFollowRedirect: true,
-		CodeProcess: request.MapCode{
-			301: func(res *http.Response) (*http.Response, error) {
-				res.StatusCode = 200
-				return res, nil
-			},
-			302: func(res *http.Response) (*http.Response, error) {
-				res.StatusCode = 200
-				return res, nil
-			},
-		},
-	}
-
-	res, err := request.Request(fmt.Sprintf("https://api.adoptium.net/v3/binary/latest/%d/ga/%s/%s/jdk/hotspot/normal/eclipse", featVersion, goos, arch), opt)
-	if err != nil {
-		return err
-	}
-
-	downloadUrl := res.Header.Get("Location")
-	if downloadUrl == "" {
-		return ErrSystem
-	}
-
-	switch strings.ToLower(filepath.Ext(path.Base(downloadUrl))) {
-	case ".tar", ".tar.gz":
-		return request.Tar(downloadUrl, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil)
-	case ".zip":
-		return request.Zip(downloadUrl, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil)
-	case ".deb":
-		res, err := request.Request(downloadUrl, nil)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-		_, pkgData, err := dpkg.NewReader(res.Body)
+	// Resolved url and hash saved in cache to install offline
+	resolved, err := cache.Default.Resolve(fmt.Sprintf("adoptium-%d-%s-%s", featVersion, goos, arch), func() (cache.Resolved, error) {
+		assets, _, err := request.JSON[[]adoptiumAsset](fmt.Sprintf("https://api.adoptium.net/v3/assets/latest/%d/hotspot?architecture=%s&image_type=jdk&os=%s&vendor=eclipse", featVersion, arch, goos), nil)
 		if err != nil {
-			return err
+			return cache.Resolved{}, err
+		} else if len(assets) == 0 || assets[0].Binary.Package.Link == "" {
+			return cache.Resolved{}, ErrSystem
 		}
-		return extractTar(pkgData, request.ExtractOptions{Strip: 0, Cwd: installPath})
+		return cache.Resolved{URL: assets[0].Binary.Package.Link, Hash: cache.Hash{Algorithm: cache.SHA256, Sum: assets[0].Binary.Package.Checksum}}, nil
+	})
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return installArchive(ctx, resolved.URL, resolved.Hash, installPath, progress)
 }
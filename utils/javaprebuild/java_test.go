@@ -1,19 +1,20 @@
 package javaprebuild
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 )
 
 func TestInstallJava(t *testing.T) {
-	if err := JavaVersion(21 + 44).InstallLatest(filepath.Join(t.TempDir(), "javatest")); err != nil && err != ErrSystem {
+	if err := JavaVersion(21+44).InstallLatest(context.Background(), filepath.Join(t.TempDir(), "javatest"), nil); err != nil && err != ErrSystem {
 		t.Error(err)
 		return
 	}
 }
 
 func TestLocal(t *testing.T) {
-	binPath, err := JavaVersion(21 + 44).Install(filepath.Join(t.TempDir(), "javatest"))
+	binPath, err := JavaVersion(21+44).Install(context.Background(), filepath.Join(t.TempDir(), "javatest"), nil)
 	if err != nil && err != ErrSystem {
 		t.Error(err)
 		return
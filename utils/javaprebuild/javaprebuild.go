@@ -5,6 +5,7 @@ import (
 	"archive/zip"
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -16,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/go-bds/utils/file_checker"
 )
 
@@ -65,6 +67,7 @@ func (ver *JavaVersion) UnmarshalText(data []byte) error {
 				return err
 			}
 			*ver = JavaVersion(version + 44)
+			return nil
 		case strings.Count(v, ".") >= 2:
 			verSplit := strings.SplitN(v, ".", 3)
 			switch v := verSplit[0]; v {
@@ -101,8 +104,9 @@ func (ver JavaVersion) String() string {
 	return "Unknown java version"
 }
 
-// Install java if local version not satisfact version and return java bin path
-func (ver JavaVersion) Install(installPath string) (string, error) {
+// Install java if local version not satisfact version and return java bin path,
+// canceled install not leave files in installPath
+func (ver JavaVersion) Install(ctx context.Context, installPath string, progress cache.Progress) (string, error) {
 	if javaBin, localVersion, err := LocalVersion(); err == nil {
 		// If version is same or more return local
 		if localVersion >= ver {
@@ -118,21 +122,23 @@ func (ver JavaVersion) Install(installPath string) (string, error) {
 	binPath, err := file_checker.FindFile(installPath, binName)
 	if err == nil {
 		return binPath, nil
-	} else if err = ver.InstallLatest(installPath); err != nil {
+	} else if err = ver.InstallLatest(ctx, installPath, progress); err != nil {
 		return "", err
 	}
 
 	return file_checker.FindFile(installPath, binName)
 }
 
-// Install last version of Java version if avaible
-func (ver JavaVersion) InstallLatest(installPath string) error {
-	if err := ver.InstallLatestAdoptium(installPath); err != nil && err != ErrSystem {
-		return err
-	} else if err = ver.InstallLatestLiberica(installPath); err != nil && err != ErrSystem {
-		return err
+// Install last version of Java version if avaible, Liberica is used if Adoptium not have version
+// or fail, like offline host with only Liberica release saved in cache
+func (ver JavaVersion) InstallLatest(ctx context.Context, installPath string, progress cache.Progress) error {
+	err := ver.InstallLatestAdoptium(ctx, installPath, progress)
+	if err == nil {
+		return nil
+	} else if libericaErr := ver.InstallLatestLiberica(ctx, installPath, progress); libericaErr != ErrSystem || err == ErrSystem {
+		return libericaErr
 	}
-	return ErrSystem
+	return err
 }
 
 // Return jar file java version
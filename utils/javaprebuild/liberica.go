@@ -2,15 +2,18 @@ package javaprebuild
 
 import (
 	"archive/tar"
+	"context"
 	"fmt"
 	"io"
 	"io/fs"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"sirherobrine23.com.br/go-bds/go-bds/utils/cache"
 	"sirherobrine23.com.br/go-bds/request/v2"
 	"sirherobrine23.com.br/sirherobrine23/go-dpkg/dpkg"
 )
@@ -27,7 +30,7 @@ type libericaVersion struct {
 }
 
 // Install latest liberica file
-func (ver JavaVersion) InstallLatestLiberica(installPath string) error {
+func (ver JavaVersion) InstallLatestLiberica(ctx context.Context, installPath string, progress cache.Progress) error {
 	requUrl, _ := url.Parse("https://api.bell-sw.com/v1/liberica/releases")
 	query := requUrl.Query()
 	query.Set("version-modifier", "latest")
@@ -70,32 +73,54 @@ func (ver JavaVersion) InstallLatestLiberica(installPath string) error {
 	}
 
 	requUrl.RawQuery = query.Encode()
-	releases, _, err := request.MakeJSON[[]libericaVersion](requUrl, nil)
+
+	// Resolved url and hash saved in cache to install offline
+	resolved, err := cache.Default.Resolve(fmt.Sprintf("liberica-%s-%s-%s%s", query.Get("version-feature"), query.Get("os"), query.Get("arch"), query.Get("bitness")), func() (cache.Resolved, error) {
+		releases, _, err := request.MakeJSON[[]libericaVersion](requUrl, nil)
+		if err != nil {
+			return cache.Resolved{}, err
+		}
+		for _, release := range releases {
+			if archiveSupported(release.DownloadURL) {
+				return cache.Resolved{URL: release.DownloadURL, Hash: cache.Hash{Algorithm: cache.SHA1, Sum: release.Sha1}}, nil
+			}
+		}
+		return cache.Resolved{}, ErrSystem
+	})
 	if err != nil {
 		return err
 	}
+	return installArchive(ctx, resolved.URL, resolved.Hash, installPath, progress)
+}
 
-	for _, release := range releases {
-		downloadUrl := release.DownloadURL
-		switch strings.ToLower(filepath.Ext(downloadUrl)) {
-		case ".tar", ".tar.gz":
-			return request.Tar(downloadUrl, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil)
-		case ".zip":
-			return request.Zip(downloadUrl, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil)
-		case ".deb":
-			res, err := request.Request(downloadUrl, nil)
-			if err != nil {
-				return err
-			}
-			defer res.Body.Close()
-			_, pkgData, err := dpkg.NewReader(res.Body)
-			if err != nil {
-				return err
-			}
-			return extractTar(pkgData, request.ExtractOptions{Strip: 0, Cwd: installPath})
+// File extension can be extracted by [installArchive]
+func archiveSupported(downloadUrl string) bool {
+	name := strings.ToLower(path.Base(downloadUrl))
+	return strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".deb")
+}
+
+// Extract java from tar, zip or deb file, return ErrSystem to other files.
+// Files are extracted to temporary folder and moved to installPath after extract
+func installArchive(ctx context.Context, downloadUrl string, hash cache.Hash, installPath string, progress cache.Progress) error {
+	switch name := strings.ToLower(path.Base(downloadUrl)); {
+	case strings.HasSuffix(name, ".tar"), strings.HasSuffix(name, ".tar.gz"):
+		return cache.Default.Tar(ctx, downloadUrl, hash, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil, progress)
+	case strings.HasSuffix(name, ".zip"):
+		return cache.Default.Zip(ctx, downloadUrl, hash, request.ExtractOptions{Strip: 1, Cwd: installPath}, nil, progress)
+	case strings.HasSuffix(name, ".deb"):
+		file, err := cache.Default.Open(ctx, downloadUrl, hash, nil, progress)
+		if err != nil {
+			return err
 		}
+		defer file.Close()
+		_, pkgData, err := dpkg.NewReader(file)
+		if err != nil {
+			return err
+		}
+		return cache.InstallFolder(installPath, func(staging string) error {
+			return extractTar(pkgData, request.ExtractOptions{Strip: 0, Cwd: staging})
+		})
 	}
-
 	return ErrSystem
 }
 
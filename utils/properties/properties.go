@@ -125,6 +125,27 @@ func (Object) ValueString() string { return "" }
 func (Object) ValueUint() uint64   { return 0 }
 func (Object) ValueFloat() float64 { return 0 }
 
+// Convert nested objects to dotted keys, example "query.port"
+func Flat(node Node) map[string]string {
+	keys := map[string]string{}
+	flat("", node, keys)
+	return keys
+}
+
+func flat(prefix string, node Node, keys map[string]string) {
+	object, ok := node.(*Object)
+	if !ok {
+		keys[prefix] = node.ValueString()
+		return
+	}
+	for key, value := range object.MapValues {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flat(key, value, keys)
+	}
+}
+
 type Slice struct {
 	nodeName string
 	dadNode  Node
@@ -207,7 +228,7 @@ func (b *Bool) Value() any                     { return b.v }
 func (b *Bool) ValueBool() bool                { return b.v }
 func (Bool) ValueKey(string) (Node, bool)      { return nil, false }
 func (Bool) ValueIndex(index int) (Node, bool) { return nil, false }
-func (Bool) ValueString() string               { return "" }
+func (b *Bool) ValueString() string            { return strconv.FormatBool(b.v) }
 func (Bool) ValueInt() int64                   { return 0 }
 func (Bool) ValueUint() uint64                 { return 0 }
 func (Bool) ValueFloat() float64               { return 0 }
@@ -226,7 +247,7 @@ func (f *Float) Value() any                     { return f.f }
 func (f *Float) ValueFloat() float64            { return f.f }
 func (Float) ValueKey(string) (Node, bool)      { return nil, false }
 func (Float) ValueIndex(index int) (Node, bool) { return nil, false }
-func (Float) ValueString() string               { return "" }
+func (f *Float) ValueString() string            { return strconv.FormatFloat(f.f, 'f', -1, 64) }
 func (Float) ValueInt() int64                   { return 0 }
 func (Float) ValueUint() uint64                 { return 0 }
 func (Float) ValueBool() bool                   { return false }
@@ -246,7 +267,7 @@ func (i *Int) MarshalJSON() ([]byte, error)   { return fmt.Appendf(nil, "%d", i.
 func (i *Int) Value() any                     { return i.v }
 func (i *Int) ValueInt() int64                { return i.v }
 func (i *Int) ValueUint() uint64              { return uint64(i.v) }
-func (Int) ValueString() string               { return "" }
+func (i *Int) ValueString() string            { return strconv.FormatInt(i.v, 10) }
 func (Int) ValueFloat() float64               { return 0 }
 func (Int) ValueBool() bool                   { return false }
 
@@ -33,7 +33,6 @@ type Reader struct {
 	r         io.Reader
 	locked    bool
 	rootValue *Object
-	err       error
 }
 
 // Unmarshal data to target point
@@ -78,94 +77,74 @@ func (r *Reader) scanLines() error {
 	r.locked = true
 	r.rootValue = &Object{nodeName: "", dadNode: nil, MapValues: map[string]Node{}}
 
-	endHeader := 0
-	bufferLocal := make([]byte, 4096)
-	for {
-		if r.err == io.EOF {
-			r.err = nil
-			break
-		} else if r.err == nil {
-			endHeader, r.err = r.r.Read(bufferLocal[endHeader:])
-			if r.err != nil && r.err != io.EOF {
-				return r.err
-			}
-		} else {
-			return r.err
-		}
+	data, err := io.ReadAll(r.r)
+	if err != nil {
+		return err
+	}
+
+	// Join multiline values
+	textToProcess := string(data)
+	for strings.Contains(textToProcess, "\\\n") {
+		before, after, _ := strings.Cut(textToProcess, "\\\n")
+		textToProcess = before + strings.TrimLeftFunc(after, unicode.IsSpace)
+	}
 
-		restartHead := true
-		textToProcess := string(bufferLocal[:endHeader])
-		for strings.Contains(textToProcess, "\\\n") {
-			before, after, _ := strings.Cut(textToProcess, "\\\n")
-			if r.err == nil && (after == "" || strings.HasSuffix(textToProcess, "\\\n")) {
-				endHeader = bytes.LastIndex(bufferLocal[:endHeader-2], []byte("\n"))
-				restartHead = false
+	textToProcess = strings.TrimSpace(textToProcess)
+	for textToProcess != "" {
+		if textToProcess[0] == '\n' {
+			if textToProcess = textToProcess[1:]; textToProcess == "" {
 				break
 			}
-			textToProcess = before + strings.TrimLeftFunc(after, unicode.IsSpace)
 		}
 
-		if restartHead {
-			endHeader = 0
-		}
-
-		textToProcess = strings.TrimSpace(textToProcess)
-		for textToProcess != "" {
-			if textToProcess[0] == '\n' {
-				if textToProcess = textToProcess[1:]; textToProcess == "" {
-					break
-				}
-			}
-
-			// Check if is comment
-			for len(textToProcess) > 0 && (textToProcess[0] == '#' || textToProcess[0] == '!') {
-				switch findBreak := strings.Index(textToProcess, "\n"); findBreak {
-				case -1:
-					textToProcess = ""
-				default:
-					textToProcess = textToProcess[findBreak+1:]
-				}
+		// Check if is comment
+		for len(textToProcess) > 0 && (textToProcess[0] == '#' || textToProcess[0] == '!') {
+			switch findBreak := strings.Index(textToProcess, "\n"); findBreak {
+			case -1:
+				textToProcess = ""
+			default:
+				textToProcess = textToProcess[findBreak+1:]
 			}
+		}
 
-			// if text is blank break
-			if textToProcess == "" {
-				break
-			}
+		// if text is blank break
+		if textToProcess == "" {
+			break
+		}
 
-			keyToProcess := textToProcess
-			if keyToProcess, textToProcess, _ = strings.Cut(textToProcess, "\n"); keyToProcess == "" {
-				continue
-			}
+		keyToProcess := textToProcess
+		if keyToProcess, textToProcess, _ = strings.Cut(textToProcess, "\n"); keyToProcess == "" {
+			continue
+		}
 
-			delimiter := func() int {
-				skipNextRune := false
-				for _, r := range "=:\t\f " {
-					for lineIndex := range keyToProcess {
-						if keyToProcess[lineIndex] == '\\' {
-							skipNextRune = true
-							continue
-						} else if skipNextRune {
-							skipNextRune = false
-							continue
-						} else if keyToProcess[lineIndex] == byte(r) {
-							return lineIndex
-						}
+		delimiter := func() int {
+			skipNextRune := false
+			for _, r := range "=:\t\f " {
+				for lineIndex := range keyToProcess {
+					if keyToProcess[lineIndex] == '\\' {
+						skipNextRune = true
+						continue
+					} else if skipNextRune {
+						skipNextRune = false
+						continue
+					} else if keyToProcess[lineIndex] == byte(r) {
+						return lineIndex
 					}
 				}
-				return -1
-			}()
-
-			if delimiter == -1 {
-				if err := ProcessStruct(r.rootValue, ParseNodePath(keyToProcess), ""); err != nil {
-					return err
-				}
-				continue
 			}
+			return -1
+		}()
 
-			key, value := strings.TrimRightFunc(strings.ReplaceAll(keyToProcess[:delimiter], "\\", ""), unicode.IsSpace), strings.TrimLeftFunc(keyToProcess[delimiter+1:], unicode.IsSpace)
-			if err := ProcessStruct(r.rootValue, ParseNodePath(key), value); err != nil {
+		if delimiter == -1 {
+			if err := ProcessStruct(r.rootValue, ParseNodePath(keyToProcess), ""); err != nil {
 				return err
 			}
+			continue
+		}
+
+		key, value := strings.TrimRightFunc(strings.ReplaceAll(keyToProcess[:delimiter], "\\", ""), unicode.IsSpace), strings.TrimLeftFunc(keyToProcess[delimiter+1:], unicode.IsSpace)
+		if err := ProcessStruct(r.rootValue, ParseNodePath(key), value); err != nil {
+			return err
 		}
 	}
 
@@ -185,6 +164,8 @@ func (r Reader) unmarshalPtr(values Node, ptr reflect.Value) (err error) {
 		return nil
 	} else if ptrType.Implements(reflectUntext) {
 		return ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values.ValueString()))
+	} else if ptr.CanAddr() && reflect.PointerTo(ptrType).Implements(reflectUntext) {
+		return ptr.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values.ValueString()))
 	} else if ptrType.Implements(reflectUnjson) {
 		data, err := values.MarshalJSON()
 		if err != nil {
@@ -116,3 +116,27 @@ func TestWrite(t *testing.T) {
 	}
 	t.Log(buffer.String())
 }
+
+func TestReaderLargeFile(t *testing.T) {
+	// Keys after first 4 KiB and value split with "\" in read boundary
+	file := strings.Repeat("# comment line to fill read buffer\n", 120)
+	file += "level-name=Bedrock level\nmotd=go-bds \\\n    server\n"
+	file += strings.Repeat("# more comments\n", 200) + "last-key=last value\n"
+
+	var values struct {
+		Level string `properties:"level-name"`
+		Motd  string `properties:"motd"`
+		Last  string `properties:"last-key"`
+	}
+	if err := Unmarshal([]byte(file), &values); err != nil {
+		t.Fatal(err)
+	} else if values.Level != "Bedrock level" || values.Motd != "go-bds server" || values.Last != "last value" {
+		t.Errorf("invalid values: %+v", values)
+	}
+
+	if data, err := Marshal(&values); err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(data), "last-key") {
+		t.Errorf("marshal return empty data: %q", data)
+	}
+}
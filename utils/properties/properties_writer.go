@@ -21,7 +21,10 @@ var (
 // Encode struct to bytes value
 func Marshal(ptr any) ([]byte, error) {
 	b := &bytes.Buffer{}
-	return b.Bytes(), NewWrite(b).Encode(ptr)
+	if err := NewWrite(b).Encode(ptr); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
 }
 
 type Writer struct {